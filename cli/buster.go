@@ -5,6 +5,7 @@ import (
 	"buster/lib"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"sync"
@@ -13,6 +14,7 @@ import (
 
 const ruler = "==============================================================="
 const cliProgressUpdate = 500 * time.Millisecond
+const maxFailedWordsShown = 20
 
 func banner() {
 	fmt.Printf("Gobuster v%s\n", lib.VERSION)
@@ -36,41 +38,123 @@ func GoBuster(ctx context.Context, opts *lib.Options, plugin lib.GobusterPlugin)
 		return err
 	}
 
+	//json格式的结果需要能够直接被其他程序解析,其余的信息全部输出到stderr
+	var info io.Writer = os.Stdout
+	if opts.OutputFormat != "" && opts.OutputFormat != "text" {
+		info = os.Stderr
+		gobuster.LogInfo.SetOutput(os.Stderr)
+		gobuster.LogError.SetOutput(os.Stderr)
+	}
+
+	//从检查点恢复时跳过已经处理的行,并重新输出之前的结果
+	var tracker *stateTracker
+	var replay []lib.Result
+	if opts.StateFile != "" {
+		if rp, ok := plugin.(lib.RecursivePlugin); ok && rp.Recursive() {
+			return fmt.Errorf("state files are not supported for recursive scans")
+		}
+		//缓存中还没有处理的单词无法记录在检查点中
+		if _, ok := plugin.(lib.PostRunPlugin); ok {
+			return fmt.Errorf("state files are not supported for %s", plugin.Name())
+		}
+		tracker = newStateTracker(opts)
+		if opts.Resume {
+			st, err := LoadState(opts.StateFile)
+			if err != nil {
+				return err
+			}
+			if st.Finished {
+				return fmt.Errorf("the scan in state file %q is already finished", opts.StateFile)
+			}
+			gobuster.ResumeOffset = st.Offset
+			replay = tracker.restore(st)
+		}
+	}
+
 	//分别开启各个处理阶段,开启工作流
 	var wg sync.WaitGroup
 	var o = new(outputType)
 
 	wg.Add(1)
-	go resultWorker(gobuster, opts.OutputFilename, &wg, o)
+	go resultWorker(gobuster, opts.OutputFilename, replay, tracker, &wg, o)
+
+	if tracker != nil {
+		wg.Add(1)
+		go stateWorker(ctxCancel, gobuster, tracker, &wg)
+	}
 
 	wg.Add(1)
 	go errorWorker(gobuster, &wg, o)
 
-	/*//是否开启进度条
+	//是否开启进度条
 	if !opts.Quiet && !opts.NoProgress {
 		wg.Add(1)
-		//TODO:processbar
-	}*/
+		go progressWorker(ctxCancel, gobuster, &wg, o)
+	}
 
 	//fan-out
 	err = gobuster.Run(ctxCancel)
 
 	cancel()
 	wg.Wait() //等待所有的工作完毕
+
+	//保存最终的检查点,被中断时可以通过--resume继续
+	if tracker != nil {
+		interrupted := ctx.Err() != nil
+		if serr := tracker.save(opts.StateFile, gobuster.Processed(), err == nil && !interrupted); serr != nil {
+			gobuster.LogError.Printf("[!] %v", serr)
+		} else if interrupted {
+			fmt.Fprintf(os.Stderr, "\n[!] State saved to %s, resume with --resume %s\n", opts.StateFile, opts.StateFile)
+		}
+	}
 	if err != nil {
 		return err
 	}
 
+	if err := reportFailedWords(gobuster, o); err != nil {
+		return err
+	}
+
 	if !opts.Quiet {
 		// clear stderr progress
 		fmt.Fprintf(os.Stderr, "\r%s\n", rightPad("", " ", o.MaxCharsWritten))
-		fmt.Println(ruler)
+		fmt.Fprintln(info, ruler)
 		gobuster.LogInfo.Println("Finished")
-		fmt.Println(ruler)
+		fmt.Fprintln(info, ruler)
+	}
+
+	return nil
+}
+
+// reportFailedWords 汇总重试后仍然失败的单词,指定了文件时写入文件以便重新执行
+func reportFailedWords(g *lib.Gobuster, output *outputType) error {
+	words := g.FailedWords()
+	if len(words) == 0 {
+		return nil
+	}
+
+	if g.Opts.FailedFilename != "" {
+		content := strings.Join(words, "\n") + "\n"
+		if err := os.WriteFile(g.Opts.FailedFilename, []byte(content), 0o644); err != nil {
+			return fmt.Errorf("[!] Unable to write failed words to file %w", err)
+		}
 	}
 
+	if !g.Opts.Quiet {
+		fmt.Fprintf(os.Stderr, "\r%s\r", rightPad("", " ", output.MaxCharsWritten))
+		if g.Opts.FailedFilename != "" {
+			g.LogError.Printf("[!] %d words failed permanently, written to %s", len(words), g.Opts.FailedFilename)
+		} else {
+			shown := words
+			if len(shown) > maxFailedWordsShown {
+				shown = append(shown[:maxFailedWordsShown:maxFailedWordsShown], "...")
+			}
+			g.LogError.Printf("[!] %d words failed permanently: %s", len(words), strings.Join(shown, ","))
+		}
+	}
 	return nil
 }
+
 func rightPad(s string, padStr string, overallen int) string {
 	strLen := len(s)
 	if overallen <= strLen {
@@ -88,7 +172,7 @@ func writeToFile(f *os.File, s string) error {
 	return nil
 }
 
-func resultWorker(g *lib.Gobuster, filename string, wg *sync.WaitGroup, output *outputType) {
+func resultWorker(g *lib.Gobuster, filename string, replay []lib.Result, tracker *stateTracker, wg *sync.WaitGroup, output *outputType) {
 	defer wg.Done()
 	var f *os.File
 	var err error
@@ -99,26 +183,57 @@ func resultWorker(g *lib.Gobuster, filename string, wg *sync.WaitGroup, output *
 		}
 		defer f.Close()
 	}
+	_, multiTarget := g.Plugin().(*lib.MultiTargetPlugin)
+	formatter := newResultFormatter(g.Opts, multiTarget)
+	//恢复扫描时先输出之前的结果,保证与未中断时的输出一致
+	for _, r := range replay {
+		s, err := formatter.Format(r)
+		if err != nil {
+			g.LogError.Fatal(err)
+		}
+		writeResult(g, f, s, output)
+	}
 	//调用接口的Results方法,获取结果通道并range获得每一个result接口值
 	for r := range g.Results() {
-		s, err := r.ResulToString()
+		if tracker != nil && !tracker.add(r) {
+			continue
+		}
+		s, err := formatter.Format(r)
 		if err != nil {
 			g.LogError.Fatal(err)
 		}
-		if s != "" {
-			s = strings.TrimSpace(s)
-			output.Mu.Lock()
-			w, _ := fmt.Printf("\r%s\n", rightPad(s, " ", output.MaxCharsWritten))
-			if (w - 1) > output.MaxCharsWritten {
-				output.MaxCharsWritten = w - 1
-			}
-			output.Mu.Unlock()
-			if f != nil {
-				err = writeToFile(f, s)
-				if err != nil {
-					g.LogError.Fatalf("error on writing output file:%v", err)
-				}
-			}
+		writeResult(g, f, s, output)
+	}
+
+	s, err := formatter.Finish()
+	if err != nil {
+		g.LogError.Fatal(err)
+	}
+	writeResult(g, f, s, output)
+}
+
+// writeResult 将格式化后的结果写入stdout以及输出文件
+func writeResult(g *lib.Gobuster, f *os.File, s string, output *outputType) {
+	if s == "" {
+		return
+	}
+	output.Mu.Lock()
+	if g.Opts.OutputFormat == "" || g.Opts.OutputFormat == "text" {
+		w, _ := fmt.Printf("\r%s\n", rightPad(s, " ", output.MaxCharsWritten))
+		if (w - 1) > output.MaxCharsWritten {
+			output.MaxCharsWritten = w - 1
+		}
+	} else {
+		//json不能带有填充的空白,先清除stderr上的进度条再输出
+		if output.MaxCharsWritten > 0 {
+			fmt.Fprintf(os.Stderr, "\r%s\r", rightPad("", " ", output.MaxCharsWritten))
+		}
+		fmt.Println(s)
+	}
+	output.Mu.Unlock()
+	if f != nil {
+		if err := writeToFile(f, s); err != nil {
+			g.LogError.Fatalf("error on writing output file:%v", err)
 		}
 	}
 }
@@ -129,9 +244,92 @@ func errorWorker(g *lib.Gobuster, wg *sync.WaitGroup, output *outputType) {
 	for e := range g.Errors() {
 		if !g.Opts.Quiet && !g.Opts.NoError { //Quiet和error同时不为false时打印错误
 			output.Mu.Lock()
+			//先清除stderr上的进度条,避免与错误信息混在同一行
+			if output.MaxCharsWritten > 0 {
+				fmt.Fprintf(os.Stderr, "\r%s\r", rightPad("", " ", output.MaxCharsWritten))
+			}
 			g.LogError.Printf("[!] %v", e)
 			output.Mu.Unlock()
 		}
 	}
 
 }
+
+// stateWorker 定时保存检查点,直到ctx被取消(最终的检查点在Run结束后保存)
+func stateWorker(ctx context.Context, g *lib.Gobuster, tracker *stateTracker, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	tick := time.NewTicker(cliStateUpdate)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-tick.C:
+			if err := tracker.save(g.Opts.StateFile, g.Processed(), false); err != nil {
+				g.LogError.Printf("[!] %v", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// progressWorker 定时在stderr上刷新进度条,直到ctx被取消
+func progressWorker(ctx context.Context, g *lib.Gobuster, wg *sync.WaitGroup, output *outputType) {
+	defer wg.Done()
+
+	tick := time.NewTicker(cliProgressUpdate)
+	defer tick.Stop()
+	start := time.Now()
+	unit := "req"
+	if pu, ok := g.Plugin().(lib.ProgressUnitPlugin); ok {
+		unit = pu.ProgressUnit()
+	}
+
+	for {
+		select {
+		case <-tick.C:
+			issued, expected, resumed, errors := g.Progress()
+			s := progressString(issued, expected, resumed, errors, time.Since(start), g.Opts.Wordlist == "-", unit)
+			if s == "" {
+				continue
+			}
+
+			output.Mu.Lock()
+			w, _ := fmt.Fprintf(os.Stderr, "\r%s", rightPad(s, " ", output.MaxCharsWritten))
+			if (w - 1) > output.MaxCharsWritten {
+				output.MaxCharsWritten = w - 1
+			}
+			output.Mu.Unlock()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// progressString 根据已发起的请求数生成进度信息,从stdin读取字典时总数未知,只显示已完成的数量,
+// unit为速度的单位(默认为req);速度和剩余时间只按照本次运行实际发起的请求(issued-resumed)计算
+func progressString(issued, expected, resumed, errors int, elapsed time.Duration, unknownTotal bool, unit string) string {
+	var rate float64
+	if elapsed > 0 {
+		rate = float64(issued-resumed) / elapsed.Seconds()
+	}
+
+	if unknownTotal {
+		return fmt.Sprintf("Progress: %d [%.0f %s/s] [Errors: %d]", issued, rate, unit, errors)
+	}
+	//字典还未统计完毕
+	if expected <= 0 {
+		return ""
+	}
+
+	eta := "-"
+	if rate > 0 && issued < expected {
+		remaining := time.Duration(float64(expected-issued) / rate * float64(time.Second))
+		eta = remaining.Round(time.Second).String()
+	} else if issued >= expected {
+		eta = "0s"
+	}
+	return fmt.Sprintf("Progress: %d / %d (%3.2f%%) [%.0f %s/s] [Errors: %d] [ETA: %s]",
+		issued, expected, float64(issued)*100.0/float64(expected), rate, unit, errors, eta)
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+	"strconv"
+)
+
+var cmdConfig *cobra.Command
+
+func init() {
+	cmdConfig = &cobra.Command{
+		Use:   "config",
+		Short: "Manage the config file",
+	}
+
+	cmdConfigDump := &cobra.Command{
+		Use:   "dump [command]",
+		Short: "Print the effective configuration after merging the config file, profile and defaults",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  runConfigDump,
+	}
+	cmdConfig.AddCommand(cmdConfigDump)
+
+	rootCmd.AddCommand(cmdConfig)
+}
+
+// runConfigDump 以配置文件的格式输出合并后的配置,没有指定命令时只输出全局参数
+func runConfigDump(cmd *cobra.Command, args []string) error {
+	target := rootCmd
+	if len(args) == 1 {
+		target = findCommand(args[0])
+		if target == nil || target == cmdConfig {
+			return fmt.Errorf("unknown command %q", args[0])
+		}
+	}
+
+	//--config和--profile属于rootCmd的persistent flag,与target共享
+	if err := applyConfig(target); err != nil {
+		return err
+	}
+
+	values := make(map[string]interface{})
+	flags := commandFlags(target)
+	if target == rootCmd {
+		flags = rootCmd.PersistentFlags()
+	}
+	flags.VisitAll(func(f *pflag.Flag) {
+		switch f.Name {
+		case "help", "config", "profile", "resume":
+			return
+		}
+		values[f.Name] = flagValue(f)
+	})
+
+	var out interface{} = map[string]interface{}{"defaults": values}
+	if target != rootCmd {
+		out = map[string]interface{}{"commands": map[string]interface{}{target.Name(): values}}
+	}
+	data, err := yaml.Marshal(out)
+	if err != nil {
+		return fmt.Errorf("error on dumping config: %w", err)
+	}
+	fmt.Print(string(data))
+	return nil
+}
+
+// flagValue 按照flag的类型返回其值,便于输出为yaml
+func flagValue(f *pflag.Flag) interface{} {
+	if sv, ok := f.Value.(pflag.SliceValue); ok {
+		return sv.GetSlice()
+	}
+	s := f.Value.String()
+	switch f.Value.Type() {
+	case "bool":
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b
+		}
+	case "int":
+		if i, err := strconv.Atoi(s); err == nil {
+			return i
+		}
+	}
+	return s
+}
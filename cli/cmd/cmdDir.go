@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"github.com/spf13/cobra"
 	"log"
+	"strings"
 )
 
 var cmdDir *cobra.Command
@@ -36,10 +37,14 @@ func init() {
 	cmdDir.Flags().BoolP("add-slash", "f", false, "Append / to each request")
 	cmdDir.Flags().BoolP("discover-backup", "d", false, "Upon finding a file search for backup files")
 	cmdDir.Flags().IntSlice("exclude-length", []int{}, "exclude the following content length (completely ignores the status). Supply multiple times to exclude multiple sizes.")
+	cmdDir.Flags().BoolP("recursive", "R", false, "Recursively scan discovered directories")
+	cmdDir.Flags().Int("max-depth", 3, "Maximum recursion depth when scanning recursively")
+	cmdDir.Flags().StringSlice("probe-methods", []string{}, "HTTP methods to try on every hit to find the allowed ones (e.g. OPTIONS,PUT,DELETE,PATCH,TRACE)")
+	addMatcherOptions(cmdDir)
 
 	//设置在执行Run之前需要执行的函数(将wordlist设置为必备的参数)
 	cmdDir.PersistentPreRun = func(cmd *cobra.Command, args []string) {
-		configureGlobalOptions()
+		configureGlobalOptions(cmd)
 	}
 
 	//添加至rootCmd
@@ -54,7 +59,12 @@ func runDir(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("error on parsing args:%w", err)
 	}
 	//2.创建插件对象
-	plugin, err := dir.NewGobusterDir(globalopts, pluginopts)
+	//指定了url-file时每个目标使用一份独立的配置
+	plugin, err := newTargetPlugin(cmdDir, globalopts, &pluginopts.HTTPOptions, func(url string) (lib.GobusterPlugin, error) {
+		opts := *pluginopts
+		opts.URL = url
+		return dir.NewGobusterDir(globalopts, &opts)
+	})
 	if err != nil {
 		return fmt.Errorf("error on creating gobusterdir: %w", err)
 	}
@@ -67,7 +77,7 @@ func runDir(cmd *cobra.Command, args []string) error {
 
 func parseDirOptions() (*lib.Options, *dir.OptionsDir, error) {
 	//获取全局的配置
-	globalopts, err := parseGolobalOptions()
+	globalopts, err := parseGolobalOptions(cmdDir)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -80,17 +90,19 @@ func parseDirOptions() (*lib.Options, *dir.OptionsDir, error) {
 	if err != nil {
 		return nil, nil, err
 	}
-	plugin.Password = httpOpts.Password
-	plugin.URL = httpOpts.URL
-	plugin.UserAgent = httpOpts.UserAgent
-	plugin.Username = httpOpts.Username
-	plugin.Proxy = httpOpts.Proxy
-	plugin.Cookies = httpOpts.Cookies
-	plugin.Timeout = httpOpts.Timeout
-	plugin.FollowRedirect = httpOpts.FollowRedirect
-	plugin.NoTLSValidation = httpOpts.NoTLSValidation
-	plugin.Headers = httpOpts.Headers
-	plugin.Method = httpOpts.Method
+	plugin.HTTPOptions = httpOpts
+
+	if err := rejectRequestBody(cmdDir); err != nil {
+		return nil, nil, err
+	}
+
+	//request-file的路径中可以使用关键字标记单词的位置,dir模式只支持位于路径末尾
+	if before, after, ok := strings.Cut(plugin.URL, lib.FuzzKeyword); ok {
+		if after != "" {
+			return nil, nil, fmt.Errorf("the %s keyword is only supported at the end of the path in dir mode", lib.FuzzKeyword)
+		}
+		plugin.URL = before
+	}
 
 	plugin.Extensions, err = cmdDir.Flags().GetString("extensions")
 	if err != nil {
@@ -137,17 +149,17 @@ func parseDirOptions() (*lib.Options, *dir.OptionsDir, error) {
 		return nil, nil, fmt.Errorf("invalid value for add-slash: %w", err)
 	}
 
-	plugin.Expanded, err = cmdDir.Flags().GetBool("expanded")
+	globalopts.Expanded, err = cmdDir.Flags().GetBool("expanded")
 	if err != nil {
 		return nil, nil, fmt.Errorf("invalid value for expanded: %w", err)
 	}
 
-	plugin.NoStatus, err = cmdDir.Flags().GetBool("no-status")
+	globalopts.NoStatus, err = cmdDir.Flags().GetBool("no-status")
 	if err != nil {
 		return nil, nil, fmt.Errorf("invalid value for no-status: %w", err)
 	}
 
-	plugin.HideLength, err = cmdDir.Flags().GetBool("hide-length")
+	globalopts.HideLength, err = cmdDir.Flags().GetBool("hide-length")
 	if err != nil {
 		return nil, nil, fmt.Errorf("invalid value for hide-length: %w", err)
 	}
@@ -162,6 +174,32 @@ func parseDirOptions() (*lib.Options, *dir.OptionsDir, error) {
 		return nil, nil, fmt.Errorf("invalid value for excludelength: %w", err)
 	}
 
+	plugin.Recursive, err = cmdDir.Flags().GetBool("recursive")
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid value for recursive: %w", err)
+	}
+
+	plugin.MaxDepth, err = cmdDir.Flags().GetInt("max-depth")
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid value for max-depth: %w", err)
+	}
+	if plugin.Recursive && plugin.MaxDepth <= 0 {
+		return nil, nil, fmt.Errorf("max-depth must be bigger than 0")
+	}
+	if plugin.Recursive && plugin.URL == "" {
+		return nil, nil, fmt.Errorf("recursive scanning is not supported together with url-file")
+	}
+
+	plugin.ProbeMethods, err = cmdDir.Flags().GetStringSlice("probe-methods")
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid value for probe-methods: %w", err)
+	}
+
+	plugin.Matchers, err = parseMatcherOptions(cmdDir)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	return globalopts, plugin, nil
 
 }
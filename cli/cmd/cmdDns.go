@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"buster/cli"
+	"buster/internal/dns"
+	"buster/lib"
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"log"
+	"net"
+	"time"
+)
+
+var cmdDNS *cobra.Command
+
+func init() {
+	cmdDNS = &cobra.Command{
+		Use:   "dns",
+		Short: "dns mode",
+		RunE:  runDNS,
+	}
+
+	cmdDNS.Flags().StringP("domain", "d", "", "The target domain")
+	cmdDNS.Flags().BoolP("show-ips", "i", false, "Show IP addresses")
+	cmdDNS.Flags().BoolP("show-cname", "c", false, "Show CNAME records (cannot be used with '-i' option)")
+	cmdDNS.Flags().DurationP("timeout", "", time.Second, "DNS resolver timeout")
+	cmdDNS.Flags().BoolP("wildcard", "", false, "Force continued operation when wildcard found")
+	cmdDNS.Flags().StringP("resolver", "r", "", "Use custom DNS server (format server.com or server.com:port)")
+	if err := cmdDNS.MarkFlagRequired("domain"); err != nil {
+		log.Fatalf("error on marking flag as required: %v", err)
+	}
+
+	cmdDNS.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		configureGlobalOptions(cmd)
+	}
+
+	rootCmd.AddCommand(cmdDNS)
+}
+
+func runDNS(cmd *cobra.Command, args []string) error {
+	globalopts, pluginopts, err := parseDNSOptions()
+	if err != nil {
+		return fmt.Errorf("error on parsing args:%w", err)
+	}
+
+	plugin, err := dns.NewGobusterDNS(globalopts, pluginopts)
+	if err != nil {
+		return fmt.Errorf("error on creating gobusterdns: %w", err)
+	}
+
+	if err := cli.GoBuster(mainCtx, globalopts, plugin); err != nil {
+		var wErr *dns.ErrWildcard
+		if errors.As(err, &wErr) {
+			return fmt.Errorf("%w. To force processing of Wildcard DNS, specify the '--wildcard' switch", wErr)
+		}
+		return err
+	}
+	return nil
+}
+
+func parseDNSOptions() (*lib.Options, *dns.OptionsDNS, error) {
+	globalopts, err := parseGolobalOptions(cmdDNS)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	plugin := dns.NewOptionsDNS()
+
+	plugin.Domain, err = cmdDNS.Flags().GetString("domain")
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid value for domain: %w", err)
+	}
+
+	plugin.ShowIPs, err = cmdDNS.Flags().GetBool("show-ips")
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid value for show-ips: %w", err)
+	}
+
+	plugin.ShowCNAME, err = cmdDNS.Flags().GetBool("show-cname")
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid value for show-cname: %w", err)
+	}
+
+	if plugin.ShowIPs && plugin.ShowCNAME {
+		return nil, nil, fmt.Errorf("show-ips and show-cname can not be used together")
+	}
+
+	plugin.WildcardForced, err = cmdDNS.Flags().GetBool("wildcard")
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid value for wildcard: %w", err)
+	}
+
+	plugin.Timeout, err = cmdDNS.Flags().GetDuration("timeout")
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid value for timeout: %w", err)
+	}
+
+	plugin.Resolver, err = cmdDNS.Flags().GetString("resolver")
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid value for resolver: %w", err)
+	}
+
+	//未指定端口时默认使用53
+	if plugin.Resolver != "" {
+		if _, _, err := net.SplitHostPort(plugin.Resolver); err != nil {
+			plugin.Resolver = net.JoinHostPort(plugin.Resolver, "53")
+		}
+	}
+
+	return globalopts, plugin, nil
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"buster/cli"
+	"buster/helper"
+	"buster/internal/fuzz"
+	"buster/lib"
+	"fmt"
+	"github.com/spf13/cobra"
+	"log"
+)
+
+var cmdFuzz *cobra.Command
+
+func init() {
+	cmdFuzz = &cobra.Command{
+		Use:   "fuzz",
+		Short: "Uses fuzzing mode. Replaces the keyword FUZZ in the URL, Headers, Cookies and the request body",
+		RunE:  runFuzz,
+	}
+	if err := addCommonHTTPOptions(cmdFuzz); err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	cmdFuzz.Flags().StringP("excludestatuscodes", "b", "", "Excluded status codes")
+	cmdFuzz.Flags().IntSlice("exclude-length", []int{}, "exclude the following content length (completely ignores the status). Supply multiple times to exclude multiple sizes.")
+	cmdFuzz.Flags().StringP("body", "B", "", "Request body")
+	cmdFuzz.Flags().Bool("raw-url", false, "Insert words into the URL as they are instead of escaping them for the path or query")
+
+	addMatcherOptions(cmdFuzz)
+
+	cmdFuzz.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		configureGlobalOptions(cmd)
+	}
+
+	rootCmd.AddCommand(cmdFuzz)
+}
+
+func runFuzz(cmd *cobra.Command, args []string) error {
+	globalopts, pluginopts, err := parseFuzzOptions()
+	if err != nil {
+		return fmt.Errorf("error on parsing args:%w", err)
+	}
+
+	plugin, err := newTargetPlugin(cmdFuzz, globalopts, &pluginopts.HTTPOptions, func(url string) (lib.GobusterPlugin, error) {
+		opts := *pluginopts
+		opts.URL = url
+		return fuzz.NewGobusterFuzz(globalopts, &opts)
+	})
+	if err != nil {
+		return fmt.Errorf("error on creating gobusterfuzz: %w", err)
+	}
+
+	if err := cli.GoBuster(mainCtx, globalopts, plugin); err != nil {
+		return err
+	}
+	return nil
+}
+
+func parseFuzzOptions() (*lib.Options, *fuzz.OptionsFuzz, error) {
+	globalopts, err := parseGolobalOptions(cmdFuzz)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	plugin := fuzz.NewOptionsFuzz()
+
+	httpOpts, err := parseCommonHTTPOptions(cmdFuzz)
+	if err != nil {
+		return nil, nil, err
+	}
+	plugin.HTTPOptions = httpOpts
+
+	plugin.ExcludedStatusCodes, err = cmdFuzz.Flags().GetString("excludestatuscodes")
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid value for excludestatuscodes: %w", err)
+	}
+	ret, err := helper.ParseCommaSeparatedInt(plugin.ExcludedStatusCodes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid value for excludestatuscodes: %w", err)
+	}
+	plugin.ExcludedStatusCodesParsed = ret
+
+	plugin.ExcludeLength, err = cmdFuzz.Flags().GetIntSlice("exclude-length")
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid value for exclude-length: %w", err)
+	}
+
+	plugin.RawURL, err = cmdFuzz.Flags().GetBool("raw-url")
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid value for raw-url: %w", err)
+	}
+
+	plugin.RequestBody, err = cmdFuzz.Flags().GetString("body")
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid value for body: %w", err)
+	}
+	//没有指定body时使用request-file中的请求体
+	if !cmdFuzz.Flags().Changed("body") {
+		rawRequest, err := parseRequestFile(cmdFuzz)
+		if err != nil {
+			return nil, nil, err
+		}
+		if rawRequest != nil {
+			plugin.RequestBody = rawRequest.Body
+		}
+	}
+
+	plugin.Matchers, err = parseMatcherOptions(cmdFuzz)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return globalopts, plugin, nil
+}
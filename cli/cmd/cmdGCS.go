@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"buster/cli"
+	"buster/internal/gcs"
+	"buster/lib"
+	"fmt"
+	"github.com/spf13/cobra"
+)
+
+var cmdGCS *cobra.Command
+
+func init() {
+	cmdGCS = &cobra.Command{
+		Use:   "gcs",
+		Short: "Uses gcs bucket enumeration mode",
+		RunE:  runGCS,
+	}
+	//存储桶的名称来自字典,url作为存储服务的地址,不需要多个目标或原始请求
+	addBasicHTTPOpt(cmdGCS)
+	cmdGCS.Flags().StringP("url", "u", gcs.DefaultURL, "Base URL of the storage service")
+	addRequestOpt(cmdGCS)
+	cmdGCS.Flags().IntP("maxfiles", "", 5, "Max files to list when a bucket is public (0 = don't list)")
+
+	cmdGCS.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		configureGlobalOptions(cmd)
+	}
+
+	rootCmd.AddCommand(cmdGCS)
+}
+
+func runGCS(cmd *cobra.Command, args []string) error {
+	globalopts, pluginopts, err := parseGCSOptions()
+	if err != nil {
+		return fmt.Errorf("error on parsing args:%w", err)
+	}
+
+	plugin, err := gcs.NewGobusterGCS(globalopts, pluginopts)
+	if err != nil {
+		return fmt.Errorf("error on creating gobustergcs: %w", err)
+	}
+
+	if err := cli.GoBuster(mainCtx, globalopts, plugin); err != nil {
+		return err
+	}
+	return nil
+}
+
+func parseGCSOptions() (*lib.Options, *gcs.OptionsGCS, error) {
+	globalopts, err := parseGolobalOptions(cmdGCS)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	plugin := gcs.NewOptionsGCS()
+
+	httpOpts, err := parseCommonHTTPOptions(cmdGCS)
+	if err != nil {
+		return nil, nil, err
+	}
+	plugin.HTTPOptions = httpOpts
+
+	plugin.MaxFiles, err = cmdGCS.Flags().GetInt("maxfiles")
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid value for maxfiles: %w", err)
+	}
+	if plugin.MaxFiles < 0 {
+		return nil, nil, fmt.Errorf("maxfiles must be positive")
+	}
+
+	return globalopts, plugin, nil
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"buster/cli"
+	"buster/internal/params"
+	"buster/lib"
+	"fmt"
+	"github.com/spf13/cobra"
+)
+
+var cmdParams *cobra.Command
+
+func init() {
+	cmdParams = &cobra.Command{
+		Use:   "params",
+		Short: "Uses parameter discovery mode. Sends the words as GET parameters, or in the body for other methods",
+		RunE:  runParams,
+	}
+	//参数需要缓存到PostRun中处理,不支持多个目标
+	addBasicHTTPOpt(cmdParams)
+	cmdParams.Flags().StringP("url", "u", "", "The target URL")
+	addRequestFileOpt(cmdParams)
+	addRequestOpt(cmdParams)
+
+	cmdParams.Flags().Int("batch-size", 40, "Number of parameters to send in one request")
+	cmdParams.Flags().StringP("body", "B", "", "Request body to append the parameters to")
+
+	cmdParams.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		configureGlobalOptions(cmd)
+	}
+
+	rootCmd.AddCommand(cmdParams)
+}
+
+func runParams(cmd *cobra.Command, args []string) error {
+	globalopts, pluginopts, err := parseParamsOptions()
+	if err != nil {
+		return fmt.Errorf("error on parsing args:%w", err)
+	}
+
+	plugin, err := params.NewGobusterParams(globalopts, pluginopts)
+	if err != nil {
+		return fmt.Errorf("error on creating gobusterparams: %w", err)
+	}
+
+	if err := cli.GoBuster(mainCtx, globalopts, plugin); err != nil {
+		return err
+	}
+	return nil
+}
+
+func parseParamsOptions() (*lib.Options, *params.OptionsParams, error) {
+	globalopts, err := parseGolobalOptions(cmdParams)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	plugin := params.NewOptionsParams()
+
+	httpOpts, err := parseCommonHTTPOptions(cmdParams)
+	if err != nil {
+		return nil, nil, err
+	}
+	plugin.HTTPOptions = httpOpts
+
+	plugin.BatchSize, err = cmdParams.Flags().GetInt("batch-size")
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid value for batch-size: %w", err)
+	}
+	if plugin.BatchSize <= 0 {
+		return nil, nil, fmt.Errorf("batch-size must be bigger than 0")
+	}
+
+	plugin.RequestBody, err = cmdParams.Flags().GetString("body")
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid value for body: %w", err)
+	}
+	//没有指定body时使用request-file中的请求体
+	if !cmdParams.Flags().Changed("body") {
+		rawRequest, err := parseRequestFile(cmdParams)
+		if err != nil {
+			return nil, nil, err
+		}
+		if rawRequest != nil {
+			plugin.RequestBody = rawRequest.Body
+		}
+	}
+
+	return globalopts, plugin, nil
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"buster/cli"
+	"buster/internal/s3"
+	"buster/lib"
+	"fmt"
+	"github.com/spf13/cobra"
+)
+
+var cmdS3 *cobra.Command
+
+func init() {
+	cmdS3 = &cobra.Command{
+		Use:   "s3",
+		Short: "Uses aws bucket enumeration mode",
+		RunE:  runS3,
+	}
+
+	addBasicHTTPOpt(cmdS3)
+	cmdS3.Flags().String("endpoint", s3.DefaultEndpoint, "S3 compatible endpoint to enumerate the buckets of")
+	cmdS3.Flags().Bool("path-style", false, "Address buckets as endpoint/bucket instead of bucket.endpoint")
+	cmdS3.Flags().IntP("maxfiles", "m", 5, "Max files to list when a bucket is listable (0 = don't list)")
+
+	cmdS3.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		configureGlobalOptions(cmd)
+	}
+
+	rootCmd.AddCommand(cmdS3)
+}
+
+func runS3(cmd *cobra.Command, args []string) error {
+	globalopts, pluginopts, err := parseS3Options()
+	if err != nil {
+		return fmt.Errorf("error on parsing args:%w", err)
+	}
+
+	plugin, err := s3.NewGobusterS3(globalopts, pluginopts)
+	if err != nil {
+		return fmt.Errorf("error on creating gobusters3: %w", err)
+	}
+
+	if err := cli.GoBuster(mainCtx, globalopts, plugin); err != nil {
+		return err
+	}
+	return nil
+}
+
+func parseS3Options() (*lib.Options, *s3.OptionsS3, error) {
+	globalopts, err := parseGolobalOptions(cmdS3)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	plugin := s3.NewOptionsS3()
+
+	httpOpts, err := parseBasicHTTPOptions(cmdS3)
+	if err != nil {
+		return nil, nil, err
+	}
+	plugin.BasicHTTPOptions = httpOpts
+
+	plugin.Endpoint, err = cmdS3.Flags().GetString("endpoint")
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid value for endpoint: %w", err)
+	}
+
+	plugin.PathStyle, err = cmdS3.Flags().GetBool("path-style")
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid value for path-style: %w", err)
+	}
+
+	plugin.MaxFiles, err = cmdS3.Flags().GetInt("maxfiles")
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid value for maxfiles: %w", err)
+	}
+	if plugin.MaxFiles < 0 {
+		return nil, nil, fmt.Errorf("maxfiles must be positive")
+	}
+
+	return globalopts, plugin, nil
+}
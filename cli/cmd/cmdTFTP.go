@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"buster/cli"
+	"buster/internal/tftp"
+	"buster/lib"
+	"fmt"
+	"github.com/spf13/cobra"
+	"log"
+	"net"
+	"time"
+)
+
+var cmdTFTP *cobra.Command
+
+func init() {
+	cmdTFTP = &cobra.Command{
+		Use:   "tftp",
+		Short: "tftp mode",
+		RunE:  runTFTP,
+	}
+
+	cmdTFTP.Flags().StringP("server", "s", "", "The target TFTP server (format server.com or server.com:port)")
+	cmdTFTP.Flags().DurationP("timeout", "", time.Second, "Time to wait for a response from the TFTP server")
+	if err := cmdTFTP.MarkFlagRequired("server"); err != nil {
+		log.Fatalf("error on marking flag as required: %v", err)
+	}
+
+	cmdTFTP.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		configureGlobalOptions(cmd)
+	}
+
+	rootCmd.AddCommand(cmdTFTP)
+}
+
+func runTFTP(cmd *cobra.Command, args []string) error {
+	globalopts, pluginopts, err := parseTFTPOptions()
+	if err != nil {
+		return fmt.Errorf("error on parsing args:%w", err)
+	}
+
+	plugin, err := tftp.NewGobusterTFTP(globalopts, pluginopts)
+	if err != nil {
+		return fmt.Errorf("error on creating gobustertftp: %w", err)
+	}
+
+	if err := cli.GoBuster(mainCtx, globalopts, plugin); err != nil {
+		return err
+	}
+	return nil
+}
+
+func parseTFTPOptions() (*lib.Options, *tftp.OptionsTFTP, error) {
+	globalopts, err := parseGolobalOptions(cmdTFTP)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	plugin := tftp.NewOptionsTFTP()
+
+	plugin.Server, err = cmdTFTP.Flags().GetString("server")
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid value for server: %w", err)
+	}
+
+	//未指定端口时默认使用69
+	if _, _, err := net.SplitHostPort(plugin.Server); err != nil {
+		plugin.Server = net.JoinHostPort(plugin.Server, "69")
+	}
+
+	plugin.Timeout, err = cmdTFTP.Flags().GetDuration("timeout")
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid value for timeout: %w", err)
+	}
+	if plugin.Timeout <= 0 {
+		return nil, nil, fmt.Errorf("timeout must be bigger than 0")
+	}
+
+	return globalopts, plugin, nil
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"buster/cli"
+	"buster/internal/vhost"
+	"buster/lib"
+	"fmt"
+	"github.com/spf13/cobra"
+	"log"
+)
+
+var cmdVhost *cobra.Command
+
+func init() {
+	cmdVhost = &cobra.Command{
+		Use:   "vhost",
+		Short: "vhost mode",
+		RunE:  runVhost,
+	}
+	if err := addCommonHTTPOptions(cmdVhost); err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	cmdVhost.Flags().BoolP("append-domain", "", false, "Append main domain from URL to words from wordlist. Otherwise the fully qualified domains need to be specified in the wordlist.")
+	cmdVhost.Flags().String("domain", "", "the domain to append when using an IP address as URL. If left empty and you specify a domain based URL the hostname from the URL is extracted")
+	cmdVhost.Flags().IntSlice("exclude-length", []int{}, "exclude the following content length (completely ignores the status). Supply multiple times to exclude multiple sizes.")
+
+	addMatcherOptions(cmdVhost)
+
+	cmdVhost.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		configureGlobalOptions(cmd)
+	}
+
+	rootCmd.AddCommand(cmdVhost)
+}
+
+func runVhost(cmd *cobra.Command, args []string) error {
+	globalopts, pluginopts, err := parseVhostOptions()
+	if err != nil {
+		return fmt.Errorf("error on parsing args:%w", err)
+	}
+
+	plugin, err := newTargetPlugin(cmdVhost, globalopts, &pluginopts.HTTPOptions, func(url string) (lib.GobusterPlugin, error) {
+		opts := *pluginopts
+		opts.URL = url
+		return vhost.NewGobusterVhost(globalopts, &opts)
+	})
+	if err != nil {
+		return fmt.Errorf("error on creating gobustervhost: %w", err)
+	}
+
+	if err := cli.GoBuster(mainCtx, globalopts, plugin); err != nil {
+		return err
+	}
+	return nil
+}
+
+func parseVhostOptions() (*lib.Options, *vhost.OptionsVhost, error) {
+	globalopts, err := parseGolobalOptions(cmdVhost)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	plugin := vhost.NewOptionsVhost()
+
+	httpOpts, err := parseCommonHTTPOptions(cmdVhost)
+	if err != nil {
+		return nil, nil, err
+	}
+	plugin.HTTPOptions = httpOpts
+
+	if err := rejectRequestBody(cmdVhost); err != nil {
+		return nil, nil, err
+	}
+
+	plugin.AppendDomain, err = cmdVhost.Flags().GetBool("append-domain")
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid value for append-domain: %w", err)
+	}
+
+	plugin.Domain, err = cmdVhost.Flags().GetString("domain")
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid value for domain: %w", err)
+	}
+
+	plugin.ExcludeLength, err = cmdVhost.Flags().GetIntSlice("exclude-length")
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid value for exclude-length: %w", err)
+	}
+
+	plugin.Matchers, err = parseMatcherOptions(cmdVhost)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return globalopts, plugin, nil
+}
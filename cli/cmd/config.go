@@ -0,0 +1,263 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// configAnnotation 标记值来自配置文件的flag,这些flag不会被标记为Changed
+const configAnnotation = "buster_config"
+
+// fileConfig 配置文件的结构(YAML或TOML,根据扩展名判断),key均为命令行参数的名称
+//
+//	defaults:      所有命令共用的参数
+//	commands:      按命令区分的参数,如commands.dir
+//	profiles:      通过--profile选择的一组参数
+type fileConfig struct {
+	Defaults map[string]interface{}            `yaml:"defaults" toml:"defaults"`
+	Commands map[string]map[string]interface{} `yaml:"commands" toml:"commands"`
+	Profiles map[string]map[string]interface{} `yaml:"profiles" toml:"profiles"`
+}
+
+// defaultConfigFile 返回默认的配置文件路径(如~/.config/buster/config.yaml)
+func defaultConfigFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "buster", "config.yaml")
+}
+
+// defaultConfigFiles 没有指定--config时依次尝试的配置文件
+func defaultConfigFiles() []string {
+	filename := defaultConfigFile()
+	if filename == "" {
+		return nil
+	}
+	return []string{filename, strings.TrimSuffix(filename, ".yaml") + ".toml"}
+}
+
+// parseConfig 根据扩展名解析配置文件,.toml为TOML,其余为YAML
+func parseConfig(filename string, data []byte) (*fileConfig, error) {
+	var cfg fileConfig
+	if strings.EqualFold(filepath.Ext(filename), ".toml") {
+		if err := toml.Unmarshal(data, &cfg); err != nil {
+			return nil, err
+		}
+		return &cfg, nil
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// loadConfig 读取--config指定的配置文件,没有指定时读取默认位置的配置文件(不存在时返回nil)
+func loadConfig(cmd *cobra.Command) (*fileConfig, string, error) {
+	filename, err := commandFlags(cmd).GetString("config")
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid value for config: %w", err)
+	}
+	candidates := []string{filename}
+	explicit := filename != ""
+	if !explicit {
+		candidates = defaultConfigFiles()
+	}
+
+	for _, filename := range candidates {
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			if !explicit && errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, "", fmt.Errorf("failed to read config file: %w", err)
+		}
+		cfg, err := parseConfig(filename, data)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid config file %s: %w", filename, err)
+		}
+		if err := cfg.validate(); err != nil {
+			return nil, "", fmt.Errorf("invalid config file %s: %w", filename, err)
+		}
+		return cfg, filename, nil
+	}
+	return nil, "", nil
+}
+
+// validate 检查配置文件中的参数是否存在,避免拼写错误被忽略
+func (c *fileConfig) validate() error {
+	check := func(section string, values map[string]interface{}, cmds []*cobra.Command) error {
+		for name := range values {
+			found := false
+			for _, cmd := range cmds {
+				if commandFlags(cmd).Lookup(name) != nil {
+					found = true
+					break
+				}
+			}
+			if !found || name == "config" || name == "profile" {
+				return fmt.Errorf("unknown flag %q in %s", name, section)
+			}
+		}
+		return nil
+	}
+
+	if err := check("defaults", c.Defaults, rootCmd.Commands()); err != nil {
+		return err
+	}
+	for name, values := range c.Commands {
+		cmd := findCommand(name)
+		if cmd == nil {
+			return fmt.Errorf("unknown command %q in commands", name)
+		}
+		if err := check("commands."+name, values, []*cobra.Command{cmd}); err != nil {
+			return err
+		}
+	}
+	for name, values := range c.Profiles {
+		if err := check("profiles."+name, values, rootCmd.Commands()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyConfig 将配置文件中的参数设置到cmd中,优先级为:命令行 > profile > commands > defaults
+func applyConfig(cmd *cobra.Command) error {
+	cfg, filename, err := loadConfig(cmd)
+	if err != nil {
+		return err
+	}
+	flags := commandFlags(cmd)
+	profile, err := flags.GetString("profile")
+	if err != nil {
+		return fmt.Errorf("invalid value for profile: %w", err)
+	}
+	if cfg == nil {
+		if profile != "" {
+			return fmt.Errorf("profile %q given but no config file found", profile)
+		}
+		return nil
+	}
+
+	var sections []map[string]interface{}
+	if profile != "" {
+		values, ok := cfg.Profiles[profile]
+		if !ok {
+			return fmt.Errorf("profile %q not found in config file %s", profile, filename)
+		}
+		sections = append(sections, values)
+	}
+	sections = append(sections, cfg.Commands[cmd.Name()], cfg.Defaults)
+
+	//已经设置过的参数(命令行或优先级更高的部分)不再覆盖
+	for _, values := range sections {
+		for _, name := range sortedKeys(values) {
+			f := flags.Lookup(name)
+			if f == nil || f.Changed || fromConfig(f) {
+				continue
+			}
+			if err := setFlagValue(f, values[name]); err != nil {
+				return fmt.Errorf("invalid value for %s in config file %s: %w", name, filename, err)
+			}
+			if f.Annotations == nil {
+				f.Annotations = make(map[string][]string)
+			}
+			f.Annotations[configAnnotation] = []string{filename}
+			//必选参数只检查Changed,配置文件中的值同样满足要求
+			if _, ok := f.Annotations[cobra.BashCompOneRequiredFlag]; ok {
+				f.Annotations[cobra.BashCompOneRequiredFlag] = []string{"false"}
+			}
+		}
+	}
+	return nil
+}
+
+// fromConfig flag的值是否来自配置文件
+func fromConfig(f *pflag.Flag) bool {
+	_, ok := f.Annotations[configAnnotation]
+	return ok
+}
+
+// dropConfigConflicts 互斥的参数中有在命令行中指定的时,将配置文件中设置的其余参数恢复为默认值
+func dropConfigConflicts(flags *pflag.FlagSet, names ...string) error {
+	explicit := false
+	for _, name := range names {
+		if flags.Changed(name) {
+			explicit = true
+			break
+		}
+	}
+	if !explicit {
+		return nil
+	}
+	for _, name := range names {
+		f := flags.Lookup(name)
+		if f == nil || !fromConfig(f) {
+			continue
+		}
+		if err := f.Value.Set(f.DefValue); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", name, err)
+		}
+		delete(f.Annotations, configAnnotation)
+	}
+	return nil
+}
+
+// setFlagValue 将配置文件中的值设置到flag中,列表只能用于可以接收多个值的flag
+// 不会将flag标记为Changed,Changed只表示命令行中显式指定的参数
+func setFlagValue(f *pflag.Flag, value interface{}) error {
+	switch value.(type) {
+	case map[string]interface{}, nil:
+		return fmt.Errorf("expected a value or a list")
+	}
+	//YAML中的列表为[]interface{},TOML中的列表可能是其他类型的切片
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice {
+		return f.Value.Set(fmt.Sprint(value))
+	}
+	sv, ok := f.Value.(pflag.SliceValue)
+	if !ok {
+		return fmt.Errorf("a list is not allowed")
+	}
+	values := make([]string, rv.Len())
+	for i := range values {
+		values[i] = fmt.Sprint(rv.Index(i).Interface())
+	}
+	return sv.Replace(values)
+}
+
+// commandFlags 返回cmd的所有flag(包括从rootCmd继承的)
+func commandFlags(cmd *cobra.Command) *pflag.FlagSet {
+	//InheritedFlags会将父命令的persistent flag合并至Flags中
+	cmd.InheritedFlags()
+	return cmd.Flags()
+}
+
+// findCommand 根据名称查找rootCmd的子命令
+func findCommand(name string) *cobra.Command {
+	for _, cmd := range rootCmd.Commands() {
+		if cmd.Name() == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"buster/helper"
+	"buster/lib"
+	"fmt"
+	"github.com/spf13/cobra"
+)
+
+// addMatcherOptions 添加根据响应内容匹配/过滤结果的flag
+func addMatcherOptions(cmd *cobra.Command) {
+	cmd.Flags().String("match-regex", "", "Only show responses whose body matches the regular expression")
+	cmd.Flags().String("filter-regex", "", "Hide responses whose body matches the regular expression")
+	cmd.Flags().String("match-size", "", "Only show responses with a size in the given ranges (e.g. 100-200,300,400-)")
+	cmd.Flags().String("filter-size", "", "Hide responses with a size in the given ranges (e.g. 100-200,300,400-)")
+	cmd.Flags().String("match-words", "", "Only show responses with a word count in the given ranges")
+	cmd.Flags().String("filter-words", "", "Hide responses with a word count in the given ranges")
+	cmd.Flags().String("match-lines", "", "Only show responses with a line count in the given ranges")
+	cmd.Flags().String("filter-lines", "", "Hide responses with a line count in the given ranges")
+}
+
+func parseMatcherOptions(cmd *cobra.Command) (lib.MatcherOptions, error) {
+	options := lib.MatcherOptions{}
+	var err error
+
+	options.MatchRegex, err = cmd.Flags().GetString("match-regex")
+	if err != nil {
+		return options, fmt.Errorf("invalid value for match-regex: %w", err)
+	}
+
+	options.FilterRegex, err = cmd.Flags().GetString("filter-regex")
+	if err != nil {
+		return options, fmt.Errorf("invalid value for filter-regex: %w", err)
+	}
+
+	ranges := []struct {
+		name   string
+		target *lib.RangeSet
+	}{
+		{"match-size", &options.MatchSize},
+		{"filter-size", &options.FilterSize},
+		{"match-words", &options.MatchWords},
+		{"filter-words", &options.FilterWords},
+		{"match-lines", &options.MatchLines},
+		{"filter-lines", &options.FilterLines},
+	}
+	for _, r := range ranges {
+		value, err := cmd.Flags().GetString(r.name)
+		if err != nil {
+			return options, fmt.Errorf("invalid value for %s: %w", r.name, err)
+		}
+		parsed, err := helper.ParseRanges(value)
+		if err != nil {
+			return options, fmt.Errorf("invalid value for %s: %w", r.name, err)
+		}
+		*r.target = parsed
+	}
+
+	return options, nil
+}
@@ -3,10 +3,12 @@ package cmd
 
 import (
 	"bufio"
+	"buster/cli"
 	"buster/lib"
 	"context"
 	"fmt"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"log"
 	"os"
 	"os/signal"
@@ -51,23 +53,96 @@ func init() {
 	rootCmd.PersistentFlags().IntP("threads", "t", 100, "Number of concurrent threads")
 	rootCmd.PersistentFlags().StringP("wordlist", "w", "", "Path to the wordlist")
 	rootCmd.PersistentFlags().StringP("output", "o", "", "Output file to write results to (defaults to stdout)")
+	rootCmd.PersistentFlags().String("output-format", "text", "Output format for results [text, jsonl, json]")
+	rootCmd.PersistentFlags().StringSlice("output-headers", []string{}, "Response headers to include in json output (e.g. Server,Content-Type)")
+	rootCmd.PersistentFlags().String("failed-output", "", "File to write words that failed permanently to (can be used as a wordlist to re-queue them)")
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Verbose output (errors)")
 	rootCmd.PersistentFlags().BoolP("quiet", "q", false, "Don't print the banner and other noise")
 	rootCmd.PersistentFlags().BoolP("no-progress", "z", false, "Don't display progress")
 	rootCmd.PersistentFlags().Bool("no-error", false, "Don't display errors")
 	rootCmd.PersistentFlags().StringP("pattern", "p", "", "File containing replacement patterns")
+	rootCmd.PersistentFlags().String("state-file", "", "Periodically save the scan state to this file so it can be resumed")
+	rootCmd.PersistentFlags().String("resume", "", "Resume an interrupted scan from the given state file")
+	rootCmd.PersistentFlags().String("config", "", fmt.Sprintf("Config file (YAML, or TOML with a .toml extension) to read default flag values from (default %s)", defaultConfigFile()))
+	rootCmd.PersistentFlags().String("profile", "", "Named profile from the config file to use")
 
 }
 
 //注意,不能在init中执行,否则将无法适用-h --help命令
-func configureGlobalOptions() {
+func configureGlobalOptions(cmd *cobra.Command) {
 	if err := rootCmd.MarkPersistentFlagRequired("wordlist"); err != nil {
 		log.Fatalf("error on marking flag as required: %v", err)
 	}
+
+	//恢复扫描时使用检查点中的参数(必须在检查必选参数之前设置)
+	resume, err := cmd.Flags().GetString("resume")
+	if err != nil {
+		log.Fatalf("invalid value for resume: %v", err)
+	}
+	if resume != "" {
+		if err := restoreFlags(cmd, resume); err != nil {
+			log.Fatalf("error on resuming: %v", err)
+		}
+	}
+
+	//配置文件中的值只作为没有在命令行中指定的参数的默认值
+	if err := applyConfig(cmd); err != nil {
+		log.Fatalf("error on loading config: %v", err)
+	}
+}
+
+// restoreFlags 将检查点中保存的参数设置到cmd中,命令行中显式指定的参数优先
+func restoreFlags(cmd *cobra.Command, filename string) error {
+	st, err := cli.LoadState(filename)
+	if err != nil {
+		return err
+	}
+	if st.Command != cmd.Name() {
+		return fmt.Errorf("state file %q belongs to the %q command", filename, st.Command)
+	}
+	for name, values := range st.Flags {
+		f := cmd.Flags().Lookup(name)
+		if f == nil {
+			return fmt.Errorf("unknown flag %q in state file", name)
+		}
+		if f.Changed {
+			continue
+		}
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			if err := sv.Replace(values); err != nil {
+				return fmt.Errorf("invalid value for %s: %w", name, err)
+			}
+			f.Changed = true
+			continue
+		}
+		if len(values) != 1 {
+			return fmt.Errorf("invalid value for %s in state file", name)
+		}
+		if err := cmd.Flags().Set(name, values[0]); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// snapshotFlags 记录命令行中显式指定的参数,用于保存检查点
+func snapshotFlags(cmd *cobra.Command) map[string][]string {
+	flags := make(map[string][]string)
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if f.Name == "resume" || f.Name == "state-file" || f.Name == "config" || f.Name == "profile" {
+			return
+		}
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			flags[f.Name] = sv.GetSlice()
+			return
+		}
+		flags[f.Name] = []string{f.Value.String()}
+	})
+	return flags
 }
 
 //获取全局的配置选项,供各个子命令调用
-func parseGolobalOptions() (*lib.Options, error) {
+func parseGolobalOptions(cmd *cobra.Command) (*lib.Options, error) {
 	globalopts := lib.NewOptions()
 	//依次读取flag,将全局配置赋值
 	threads, err := rootCmd.Flags().GetInt("threads")
@@ -131,6 +206,26 @@ func parseGolobalOptions() (*lib.Options, error) {
 		return nil, fmt.Errorf("invalid value for output filename: %w", err)
 	}
 
+	globalopts.OutputFormat, err = rootCmd.Flags().GetString("output-format")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for output-format: %w", err)
+	}
+	switch globalopts.OutputFormat {
+	case "text", "jsonl", "json":
+	default:
+		return nil, fmt.Errorf("invalid value for output-format: %q (must be text, jsonl or json)", globalopts.OutputFormat)
+	}
+
+	globalopts.OutputHeaders, err = rootCmd.Flags().GetStringSlice("output-headers")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for output-headers: %w", err)
+	}
+
+	globalopts.FailedFilename, err = rootCmd.Flags().GetString("failed-output")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for failed-output: %w", err)
+	}
+
 	//详细的错误信息
 	globalopts.Verbose, err = rootCmd.Flags().GetBool("verbose")
 	if err != nil {
@@ -155,5 +250,23 @@ func parseGolobalOptions() (*lib.Options, error) {
 		return nil, fmt.Errorf("invalid value for no-error: %w", err)
 	}
 
+	//保存检查点,恢复时继续写入同一个文件
+	globalopts.StateFile, err = rootCmd.Flags().GetString("state-file")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for state-file: %w", err)
+	}
+	resume, err := rootCmd.Flags().GetString("resume")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for resume: %w", err)
+	}
+	if resume != "" {
+		globalopts.StateFile = resume
+		globalopts.Resume = true
+	}
+	if globalopts.StateFile != "" {
+		globalopts.StateCommand = cmd.Name()
+		globalopts.StateFlags = snapshotFlags(cmd)
+	}
+
 	return globalopts, nil
 }
@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"buster/lib"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// resultFormatter 将结果转换为输出的文本,同一个格式化结果会同时写入stdout和输出文件
+type resultFormatter interface {
+	// Format 返回单个结果的输出,返回空字符串表示暂不输出
+	Format(r lib.Result) (string, error)
+	// Finish 在所有结果处理完毕后调用,返回剩余需要输出的内容
+	Finish() (string, error)
+}
+
+func newResultFormatter(opts *lib.Options, showTarget bool) resultFormatter {
+	switch opts.OutputFormat {
+	case "jsonl":
+		return &jsonLinesFormatter{headers: opts.OutputHeaders}
+	case "json":
+		return &jsonFormatter{headers: opts.OutputHeaders}
+	default:
+		return textFormatter{opts: opts, showTarget: showTarget}
+	}
+}
+
+// textFormatter 默认的格式,按照插件选择textRenderer,多目标扫描时在前面加上目标
+type textFormatter struct {
+	opts       *lib.Options
+	showTarget bool
+}
+
+func (t textFormatter) Format(r lib.Result) (string, error) {
+	f := r.Fields()
+	render, ok := textRenderers[f.Plugin]
+	if !ok {
+		render = renderGeneric
+	}
+	s := strings.TrimSpace(render(f, t.opts))
+	if t.showTarget {
+		s = fmt.Sprintf("[%s] %s", f.Target, s)
+	}
+	return s, nil
+}
+
+func (textFormatter) Finish() (string, error) {
+	return "", nil
+}
+
+// jsonRecord json格式中每一个结果对应的对象
+type jsonRecord struct {
+	Timestamp time.Time         `json:"timestamp"`
+	Plugin    string            `json:"plugin,omitempty"`
+	Target    string            `json:"target,omitempty"`
+	Found     bool              `json:"found"`
+	URL       string            `json:"url,omitempty"`
+	Path      string            `json:"path,omitempty"`
+	Host      string            `json:"host,omitempty"`
+	Word      string            `json:"word,omitempty"`
+	Status    int               `json:"status,omitempty"`
+	Size      *int64            `json:"size,omitempty"`
+	Duration  float64           `json:"duration_ms,omitempty"`
+	Location  string            `json:"location,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	IPs       []string          `json:"ips,omitempty"`
+	CNAME     string            `json:"cname,omitempty"`
+	Info      string            `json:"info,omitempty"`
+	Items     []string          `json:"items,omitempty"`
+	Methods   []string          `json:"methods,omitempty"`
+}
+
+func newJSONRecord(r lib.Result, headers []string) jsonRecord {
+	f := r.Fields()
+	record := jsonRecord{
+		Timestamp: time.Now(),
+		Plugin:    f.Plugin,
+		Target:    f.Target,
+		Found:     f.Found,
+		URL:       f.URL,
+		Path:      f.Path,
+		Host:      f.Host,
+		Word:      f.Word,
+		Status:    f.StatusCode,
+		Duration:  float64(f.Duration.Microseconds()) / 1000,
+		IPs:       f.IPs,
+		CNAME:     f.CNAME,
+		Info:      f.Info,
+		Items:     f.Items,
+		Methods:   f.Methods,
+	}
+	//http类的结果以及知道大小的文件才有size
+	if f.StatusCode != 0 || f.Size > 0 {
+		size := f.Size
+		record.Size = &size
+	}
+	if f.Header != nil {
+		record.Location = f.Header.Get("Location")
+		record.Headers = selectHeaders(f.Header, headers)
+	}
+	return record
+}
+
+// selectHeaders 从响应头中挑选出需要输出的部分
+func selectHeaders(header http.Header, names []string) map[string]string {
+	if len(names) == 0 {
+		return nil
+	}
+	selected := make(map[string]string)
+	for _, name := range names {
+		if v := header.Values(name); len(v) > 0 {
+			selected[http.CanonicalHeaderKey(name)] = strings.Join(v, ", ")
+		}
+	}
+	return selected
+}
+
+// jsonLinesFormatter 每个结果输出为一行json
+type jsonLinesFormatter struct {
+	headers []string
+}
+
+func (f *jsonLinesFormatter) Format(r lib.Result) (string, error) {
+	b, err := json.Marshal(newJSONRecord(r, f.headers))
+	if err != nil {
+		return "", fmt.Errorf("could not marshal result: %w", err)
+	}
+	return string(b), nil
+}
+
+func (f *jsonLinesFormatter) Finish() (string, error) {
+	return "", nil
+}
+
+// jsonFormatter 收集所有结果,结束时输出为一个json数组
+type jsonFormatter struct {
+	headers []string
+	records []jsonRecord
+}
+
+func (f *jsonFormatter) Format(r lib.Result) (string, error) {
+	f.records = append(f.records, newJSONRecord(r, f.headers))
+	return "", nil
+}
+
+func (f *jsonFormatter) Finish() (string, error) {
+	records := f.records
+	if records == nil {
+		records = []jsonRecord{}
+	}
+	b, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("could not marshal results: %w", err)
+	}
+	return string(b), nil
+}
@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"buster/lib"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const cliStateUpdate = 5 * cliProgressUpdate
+
+// State 扫描的检查点,记录命令行参数、字典的处理进度以及已经输出的结果
+type State struct {
+	Command  string              `json:"command"`
+	Flags    map[string][]string `json:"flags"`
+	Offset   int                 `json:"offset"`
+	Findings []stateFinding      `json:"findings"`
+	Finished bool                `json:"finished"`
+}
+
+// stateFinding 已经输出的结果,恢复时重新输出
+type stateFinding struct {
+	Data lib.ResultFields `json:"fields"`
+}
+
+// Fields 实现result接口,恢复时直接返回保存的数据
+func (f stateFinding) Fields() lib.ResultFields {
+	return f.Data
+}
+
+// key 用于判断重新扫描到的结果是否已经在恢复时输出过
+func (f stateFinding) key() string {
+	d := f.Data
+	return strings.Join([]string{d.Plugin, d.Target, d.URL, d.Path, d.Host, d.Word, strconv.Itoa(d.StatusCode)}, "\x00")
+}
+
+// LoadState 读取保存的检查点
+func LoadState(filename string) (*State, error) {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("could not read state file %q: %w", filename, err)
+	}
+	var st State
+	if err := json.Unmarshal(b, &st); err != nil {
+		return nil, fmt.Errorf("could not parse state file %q: %w", filename, err)
+	}
+	return &st, nil
+}
+
+// save 先写入临时文件再重命名,避免中断时留下不完整的检查点
+func (st *State) save(filename string) error {
+	b, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not marshal state: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".tmp")
+	if err != nil {
+		return fmt.Errorf("could not write state file: %w", err)
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("could not write state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("could not write state file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filename); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("could not write state file: %w", err)
+	}
+	return nil
+}
+
+// stateTracker 在扫描过程中收集需要保存的状态
+type stateTracker struct {
+	mu       sync.Mutex
+	state    State
+	replayed lib.StringSet //恢复时已经输出过的结果,重新扫描到时不再重复输出
+}
+
+func newStateTracker(opts *lib.Options) *stateTracker {
+	return &stateTracker{
+		state: State{
+			Command:  opts.StateCommand,
+			Flags:    opts.StateFlags,
+			Findings: []stateFinding{},
+		},
+		replayed: lib.NewStringSet(),
+	}
+}
+
+// restore 载入之前的结果,返回需要重新输出的结果
+func (t *stateTracker) restore(st *State) []lib.Result {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	results := make([]lib.Result, 0, len(st.Findings))
+	for _, f := range st.Findings {
+		t.state.Findings = append(t.state.Findings, f)
+		t.replayed.Add(f.key())
+		results = append(results, f)
+	}
+	return results
+}
+
+// add 记录一个新的结果,已经在恢复时输出过的返回false
+func (t *stateTracker) add(r lib.Result) bool {
+	f := stateFinding{Data: r.Fields()}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.replayed.Contains(f.key()) {
+		return false
+	}
+	t.state.Findings = append(t.state.Findings, f)
+	return true
+}
+
+func (t *stateTracker) save(filename string, offset int, finished bool) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.state.Offset = offset
+	t.state.Finished = finished
+	return t.state.save(filename)
+}
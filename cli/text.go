@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"buster/lib"
+	"fmt"
+	"strings"
+)
+
+// textRenderer 将某个插件的结果渲染为一行文本
+type textRenderer func(f lib.ResultFields, opts *lib.Options) string
+
+// textRenderers 按照插件名称选择文本格式,没有注册的插件使用renderGeneric
+var textRenderers = map[string]textRenderer{
+	"dir":    renderDir,
+	"dns":    renderDNS,
+	"vhost":  renderVhost,
+	"fuzz":   renderFuzz,
+	"s3":     renderBucket,
+	"gcs":    renderBucket,
+	"tftp":   renderTFTP,
+	"params": renderParams,
+}
+
+// foundPrefix verbose模式下同时输出未发现的结果,需要加上前缀区分
+func foundPrefix(f lib.ResultFields) string {
+	if f.Found {
+		return "Found: "
+	}
+	return "Missed: "
+}
+
+func renderDir(f lib.ResultFields, opts *lib.Options) string {
+	var sb strings.Builder
+	if opts.Verbose {
+		sb.WriteString(foundPrefix(f))
+	}
+	//是否打印完整url 或者只是相对路径
+	if opts.Expanded {
+		sb.WriteString(f.Target)
+	} else {
+		sb.WriteString("/")
+	}
+	fmt.Fprintf(&sb, "%-20s", strings.TrimPrefix(f.Path, "/"))
+	if !opts.NoStatus {
+		fmt.Fprintf(&sb, " (Status: %d)", f.StatusCode)
+	}
+	if !opts.HideLength {
+		fmt.Fprintf(&sb, " [Size: %d]", f.Size)
+	}
+	//location一般是301重定向时会被写入
+	if location := f.Header.Get("Location"); location != "" {
+		fmt.Fprintf(&sb, "[--> %s]", location)
+	}
+	//开启了probe-methods时输出允许的method
+	if len(f.Methods) > 0 {
+		fmt.Fprintf(&sb, " [Methods: %s]", strings.Join(f.Methods, ", "))
+	}
+	return sb.String()
+}
+
+func renderDNS(f lib.ResultFields, opts *lib.Options) string {
+	var sb strings.Builder
+	sb.WriteString(foundPrefix(f))
+	sb.WriteString(f.Host)
+	if len(f.IPs) > 0 {
+		fmt.Fprintf(&sb, " [%s]", strings.Join(f.IPs, ","))
+	}
+	//没有别名记录时CNAME为空
+	if f.CNAME != "" {
+		fmt.Fprintf(&sb, " [%s]", f.CNAME)
+	}
+	return sb.String()
+}
+
+func renderVhost(f lib.ResultFields, opts *lib.Options) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s%s (Status: %d) [Size: %d]", foundPrefix(f), f.Host, f.StatusCode, f.Size)
+	if location := f.Header.Get("Location"); location != "" {
+		fmt.Fprintf(&sb, " [--> %s]", location)
+	}
+	return sb.String()
+}
+
+func renderFuzz(f lib.ResultFields, opts *lib.Options) string {
+	var sb strings.Builder
+	if opts.Verbose {
+		sb.WriteString(foundPrefix(f))
+	}
+	fmt.Fprintf(&sb, "[Status=%d] [Length=%d] [Word=%s] %s", f.StatusCode, f.Size, f.Word, f.URL)
+	return sb.String()
+}
+
+// renderBucket 对象存储的结果,输出存储桶的权限以及列出的文件
+func renderBucket(f lib.ResultFields, opts *lib.Options) string {
+	var sb strings.Builder
+	if opts.Verbose {
+		sb.WriteString(foundPrefix(f))
+	}
+	sb.WriteString(f.URL)
+	if f.Info != "" {
+		fmt.Fprintf(&sb, " [%s]", f.Info)
+	}
+	if len(f.Items) > 0 {
+		fmt.Fprintf(&sb, " [Files: %s]", strings.Join(f.Items, ", "))
+	}
+	return sb.String()
+}
+
+func renderTFTP(f lib.ResultFields, opts *lib.Options) string {
+	var sb strings.Builder
+	sb.WriteString(foundPrefix(f))
+	sb.WriteString(f.Path)
+	//大小未知或者服务器返回了错误时输出说明
+	if f.Info != "" {
+		fmt.Fprintf(&sb, " [%s]", f.Info)
+	} else if f.Found {
+		fmt.Fprintf(&sb, " [Size: %d]", f.Size)
+	}
+	return sb.String()
+}
+
+func renderParams(f lib.ResultFields, opts *lib.Options) string {
+	return fmt.Sprintf("%s (Status: %d) [Size: %d] [%s]", f.Word, f.StatusCode, f.Size, f.Info)
+}
+
+// renderGeneric 没有专门格式的插件,依次输出存在的字段
+func renderGeneric(f lib.ResultFields, opts *lib.Options) string {
+	parts := []string{strings.TrimSuffix(foundPrefix(f), " ")}
+	for _, s := range []string{f.URL, f.Host, f.Word} {
+		if s != "" {
+			parts = append(parts, s)
+			break
+		}
+	}
+	if f.StatusCode != 0 {
+		parts = append(parts, fmt.Sprintf("(Status: %d) [Size: %d]", f.StatusCode, f.Size))
+	}
+	return strings.Join(parts, " ")
+}
@@ -53,3 +53,34 @@ func JoinIntSlice(s []int) string {
 	result := strings.Join(valuesText, ",")
 	return result
 }
+
+// ParseRanges 解析以逗号分隔的区间,如100-200,300,400-
+func ParseRanges(inputString string) (lib.RangeSet, error) {
+	if inputString == "" {
+		return lib.RangeSet{}, nil
+	}
+
+	ret := lib.RangeSet{}
+	for _, c := range strings.Split(inputString, ",") {
+		c = strings.TrimSpace(c)
+		minText, maxText, isRange := strings.Cut(c, "-")
+		lower, err := strconv.ParseInt(strings.TrimSpace(minText), 10, 64)
+		if err != nil || lower < 0 {
+			return lib.RangeSet{}, fmt.Errorf("invalid range given: %s", c)
+		}
+		upper := lower
+		if isRange {
+			maxText = strings.TrimSpace(maxText)
+			if maxText == "" {
+				upper = -1
+			} else {
+				upper, err = strconv.ParseInt(maxText, 10, 64)
+				if err != nil || upper < lower {
+					return lib.RangeSet{}, fmt.Errorf("invalid range given: %s", c)
+				}
+			}
+		}
+		ret.Ranges = append(ret.Ranges, lib.Range{Min: lower, Max: upper})
+	}
+	return ret, nil
+}
@@ -0,0 +1,36 @@
+package helper
+
+import (
+	"buster/lib"
+	"reflect"
+	"testing"
+)
+
+func TestParseRanges(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []lib.Range
+		err   bool
+	}{
+		{"", nil, false},
+		{"100", []lib.Range{{Min: 100, Max: 100}}, false},
+		{"100-200", []lib.Range{{Min: 100, Max: 200}}, false},
+		{"400-", []lib.Range{{Min: 400, Max: -1}}, false},
+		{" 1 - 2 , 5 ,9- ", []lib.Range{{Min: 1, Max: 2}, {Min: 5, Max: 5}, {Min: 9, Max: -1}}, false},
+		{"0-0", []lib.Range{{Min: 0, Max: 0}}, false},
+		{"-5", nil, true},
+		{"200-100", nil, true},
+		{"a-b", nil, true},
+		{"1,,2", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseRanges(tt.input)
+		if (err != nil) != tt.err {
+			t.Errorf("%q: got error %v, want error %v", tt.input, err, tt.err)
+			continue
+		}
+		if !reflect.DeepEqual(got.Ranges, tt.want) {
+			t.Errorf("%q: got %v, want %v", tt.input, got.Ranges, tt.want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package helper
+
+import (
+	"bufio"
+	"buster/lib"
+	"bytes"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// RawRequest 从原始HTTP请求(如代理工具导出的请求)中解析出的内容
+type RawRequest struct {
+	Method  string
+	URL     string
+	Headers []lib.HTTPHeader
+	Cookies string
+	Body    string
+}
+
+// 由client自行设置的header,不从原始请求中继承
+var rawRequestSkipHeaders = map[string]bool{
+	"host":              true,
+	"content-length":    true,
+	"connection":        true,
+	"transfer-encoding": true,
+	"accept-encoding":   true, //设置后client不再自动解压,会导致大小和body匹配出错
+}
+
+// ParseRawRequest 解析HTTP/1.1格式的原始请求,scheme用于请求行中没有完整url时拼接url
+func ParseRawRequest(data []byte, scheme string) (*RawRequest, error) {
+	r := bufio.NewReader(bytes.NewReader(data))
+
+	line, err := readRequestLine(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request line: %w", err)
+	}
+	parts := strings.Fields(line)
+	if len(parts) != 3 || !strings.HasPrefix(parts[2], "HTTP/") {
+		return nil, fmt.Errorf("invalid request line %q", line)
+	}
+	req := RawRequest{Method: parts[0]}
+	target := parts[1]
+
+	host := ""
+	var cookies []string
+	for {
+		line, err = readRequestLine(r)
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("invalid header: %w", err)
+		}
+		if line == "" {
+			break
+		}
+		keyAndValue := strings.SplitN(line, ":", 2)
+		if len(keyAndValue) != 2 {
+			return nil, fmt.Errorf("invalid header %q", line)
+		}
+		key := strings.TrimSpace(keyAndValue[0])
+		value := strings.TrimSpace(keyAndValue[1])
+		if key == "" {
+			return nil, fmt.Errorf("invalid header %q - name is empty", line)
+		}
+		switch lower := strings.ToLower(key); {
+		case lower == "host":
+			host = value
+		case lower == "cookie":
+			cookies = append(cookies, value)
+		case !rawRequestSkipHeaders[lower]:
+			req.Headers = append(req.Headers, lib.HTTPHeader{Name: key, Value: value})
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	req.Cookies = strings.Join(cookies, "; ")
+
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid body: %w", err)
+	}
+	req.Body = string(body)
+
+	//请求行中可能是完整的url(发往代理的请求),否则使用Host拼接
+	if strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://") {
+		req.URL = target
+	} else {
+		if host == "" {
+			return nil, fmt.Errorf("no host header found in request")
+		}
+		if !strings.HasPrefix(target, "/") {
+			return nil, fmt.Errorf("invalid request target %q", target)
+		}
+		req.URL = fmt.Sprintf("%s://%s%s", scheme, host, target)
+	}
+	if _, err := url.Parse(req.URL); err != nil {
+		return nil, fmt.Errorf("invalid url %s: %w", req.URL, err)
+	}
+	return &req, nil
+}
+
+// readRequestLine 读取一行并去掉结尾的\r\n
+func readRequestLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), err
+}
@@ -8,32 +8,38 @@ import (
 	"context"
 	"fmt"
 	"github.com/google/uuid"
+	"net/http"
+	"net/url"
+	"regexp"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"text/tabwriter"
+	"time"
 )
 
 var (
 	backupExtensions    = []string{"~", ".bak", ".bak2", ".old", ".1"}
 	backupDotExtensions = []string{".swp"}
+	//method只能由token字符组成(RFC 7230)
+	validMethod = regexp.MustCompile("^[!#$%&'*+.^_`|~0-9A-Za-z-]+$")
 )
 
-type ErrWildcard struct {
-	url        string
-	statusCode int
-	length     int64
-}
-
-func (e *ErrWildcard) Error() string {
-	return fmt.Sprintf("the server returns a status code that matches the provided options for non existing urls. %s => %d (Length: %d)", e.url, e.statusCode, e.length)
-
-}
-
 //GobusterDir dir模式的核心实现,实现plugin接口,直接发起HTTP请求的结构
 type GobusterDir struct {
 	options       *OptionsDir
 	globalopts    *lib.Options
 	http          *lib.HTTPClient
+	matcher       *lib.ResponseMatcher
 	requestPerRun *int
+	extraRequests int64 //method探测以及确认目录发起的请求数,使用atomic访问
+
+	basesMutex   sync.Mutex
+	baseDepth    map[string]int //已经加入扫描的目录及其深度,用于去重
+	pendingBases []string       //新发现但还未交给引擎的目录
+
+	wildcardMutex sync.RWMutex
+	wildcards     map[string][]wildcardFingerprint //每个目录下不存在的路径返回的响应指纹
 }
 
 // NewGobusterDir 根据全局的配置,和http的配置,生成GobusterDir(实现了plugin接口)
@@ -49,25 +55,23 @@ func NewGobusterDir(globalopts *lib.Options, opts *OptionsDir) (*GobusterDir, er
 	g := GobusterDir{
 		options:    opts,
 		globalopts: globalopts,
+		baseDepth:  make(map[string]int),
+		wildcards:  make(map[string][]wildcardFingerprint),
 	}
-	basicOptions := lib.BasicHTTPOptions{
-		Proxy:           opts.Proxy,
-		Timeout:         opts.Timeout,
-		UserAgent:       opts.UserAgent,
-		NoTLSValidation: opts.NoTLSValidation,
+	m, err := lib.NewResponseMatcher(opts.Matchers)
+	if err != nil {
+		return nil, err
 	}
+	g.matcher = m
 
-	httpOpts := lib.HTTPOptions{
-		BasicHTTPOptions: basicOptions,
-		FollowRedirect:   opts.FollowRedirect,
-		Username:         opts.Username,
-		Password:         opts.Password,
-		Headers:          opts.Headers,
-		Cookies:          opts.Cookies,
-		Method:           opts.Method,
+	for _, method := range opts.ProbeMethods {
+		if !validMethod.MatchString(method) {
+			return nil, fmt.Errorf("invalid method %q", method)
+		}
 	}
+
 	//适用http的配置创建http的client
-	h, err := lib.NewHTTPClient(&httpOpts)
+	h, err := lib.NewHTTPClient(&opts.HTTPOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -110,37 +114,163 @@ func (d *GobusterDir) PreRun(ctx context.Context) error {
 		return fmt.Errorf("unable to connect to %s: %w", d.options.URL, err)
 	}
 
-	guid := uuid.New()
-	url := fmt.Sprintf("%s%s", d.options.URL, guid)
-	if d.options.UseSlash {
-		url = fmt.Sprintf("%s/", url)
+	d.basesMutex.Lock()
+	d.baseDepth[d.options.URL] = 0
+	d.basesMutex.Unlock()
+
+	return d.wildcardCheck(ctx, d.options.URL)
+}
+
+// PreRunBase 对递归发现的目录进行通配检查
+func (d *GobusterDir) PreRunBase(ctx context.Context, base string) error {
+	return d.wildcardCheck(ctx, base)
+}
+
+// wildcardCheck 请求base下若干随机的路径,若其响应满足结果条件则说明存在通配,
+// 此时记录这些响应的指纹,之后与指纹相同的结果将被忽略而不是中止扫描
+func (d *GobusterDir) wildcardCheck(ctx context.Context, base string) error {
+	//带/与不带/的路径可能由不同的规则处理,两种都需要检测
+	name := uuid.New().String()
+	names := []string{name, name + "/"}
+	if d.options.DiscoverBackup {
+		names = append(names, fmt.Sprintf("%s%s", uuid.New(), backupExtensions[0]))
 	}
-	wildcardResp, wildcardLength, _, _, err := d.http.Request(ctx, url, lib.RequestOptions{})
-	if err != nil {
-		return err
+	//不同长度的路径,用于检测回显路径导致的大小变化
+	long := uuid.New().String()
+	names = append(names, long+long[:12])
+	for ext := range d.options.ExtensionsParsed.Set {
+		names = append(names, fmt.Sprintf("%s.%s", uuid.New(), ext))
 	}
 
-	//判断是否在排除名单
-	if helper.SliceContains(d.options.ExcludeLength, int(wildcardLength)) {
-		// we are done and ignore the request as the length is excluded
-		return nil
+	var probes []wildcardProbe
+	for _, name := range names {
+		url := fmt.Sprintf("%s%s", base, name)
+		statusCode, size, header, body, err := d.http.Request(ctx, url, lib.RequestOptions{ReturnBody: true})
+		if err != nil {
+			return err
+		}
+		if statusCode == nil {
+			return ctx.Err()
+		}
+
+		hit, err := d.isHit(*statusCode, size, header, body)
+		if err != nil {
+			return err
+		}
+		if hit {
+			probes = append(probes, newWildcardProbe(strings.TrimSuffix(name, "/"), *statusCode, size, header, body))
+		}
+	}
+
+	if len(probes) > 0 {
+		d.wildcardMutex.Lock()
+		d.wildcards[base] = newWildcardFingerprints(probes)
+		d.wildcardMutex.Unlock()
 	}
+	return nil
+}
 
+// isHit 根据状态码、排除的长度以及matcher判断响应是否满足结果条件
+func (d *GobusterDir) isHit(statusCode int, size int64, header http.Header, body []byte) (bool, error) {
 	if d.options.StatusCodesBlacklistParsed.Length() > 0 {
-		if !d.options.StatusCodesBlacklistParsed.Contains(*wildcardResp) {
-			return &ErrWildcard{url: url, statusCode: *wildcardResp, length: wildcardLength}
+		if d.options.StatusCodesBlacklistParsed.Contains(statusCode) {
+			return false, nil
 		}
 	} else if d.options.StatusCodesParsed.Length() > 0 {
-		if d.options.StatusCodesParsed.Contains(*wildcardResp) {
-			return &ErrWildcard{url: url, statusCode: *wildcardResp, length: wildcardLength}
+		if !d.options.StatusCodesParsed.Contains(statusCode) {
+			return false, nil
 		}
 	} else {
-		return fmt.Errorf("StatusCodes and StatusCodesBlacklist are both not set which should not happen")
+		return false, fmt.Errorf("StatusCodes and StatusCodesBlacklist are both not set which should not happen")
 	}
 
-	return nil
+	if helper.SliceContains(d.options.ExcludeLength, int(size)) {
+		return false, nil
+	}
+	return d.matcher.Allow(&lib.MatchResponse{StatusCode: statusCode, Size: size, Header: header, Body: body}), nil
+}
+
+// getWildcards 返回base下的通配指纹
+func (d *GobusterDir) getWildcards(base string) []wildcardFingerprint {
+	d.wildcardMutex.RLock()
+	defer d.wildcardMutex.RUnlock()
+	return d.wildcards[base]
+}
+
+// isWildcard 判断响应是否与base下的通配指纹相同
+func isWildcard(fps []wildcardFingerprint, name string, statusCode int, size int64, header http.Header, body []byte) bool {
+	if len(fps) == 0 {
+		return false
+	}
+	p := newWildcardProbe(strings.TrimSuffix(name, "/"), statusCode, size, header, body)
+	for i := range fps {
+		if fps[i].matches(p) {
+			return true
+		}
+	}
+	return false
+}
 
+// Recursive 是否开启了递归扫描
+func (d *GobusterDir) Recursive() bool {
+	return d.options.Recursive
 }
+
+// NewBases 返回新发现的目录,交由引擎进行递归扫描
+func (d *GobusterDir) NewBases() []string {
+	d.basesMutex.Lock()
+	defer d.basesMutex.Unlock()
+	bases := d.pendingBases
+	d.pendingBases = nil
+	return bases
+}
+
+// addBase 记录在base下发现的目录,超过最大深度或已经扫描过的目录将被忽略
+func (d *GobusterDir) addBase(base, dirURL string) {
+	d.basesMutex.Lock()
+	defer d.basesMutex.Unlock()
+	depth := d.baseDepth[base] + 1
+	if depth > d.options.MaxDepth {
+		return
+	}
+	if _, found := d.baseDepth[dirURL]; found {
+		return
+	}
+	d.baseDepth[dirURL] = depth
+	d.pendingBases = append(d.pendingBases, dirURL)
+}
+
+// directoryURL 根据响应判断请求的路径是否为目录,是则返回以/结尾的目录url
+func directoryURL(requestURL string, statusCode int, header http.Header) (string, bool) {
+	switch statusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		//只有跳转到请求路径加/的才认为是目录(如/admin -> /admin/)
+		location := header.Get("Location")
+		if location == "" {
+			return "", false
+		}
+		u, err := url.Parse(requestURL)
+		if err != nil {
+			return "", false
+		}
+		l, err := u.Parse(location)
+		if err != nil {
+			return "", false
+		}
+		if l.Host != u.Host || l.Path != u.Path+"/" {
+			return "", false
+		}
+		l.RawQuery = ""
+		l.Fragment = ""
+		return l.String(), true
+	case http.StatusOK, http.StatusForbidden:
+		if strings.HasSuffix(requestURL, "/") {
+			return requestURL, true
+		}
+	}
+	return "", false
+}
+
 func getBackupFilenames(word string) []string {
 	ret := make([]string, len(backupExtensions)+len(backupDotExtensions))
 	i := 0
@@ -156,77 +286,189 @@ func getBackupFilenames(word string) []string {
 	return ret
 }
 func (d *GobusterDir) Run(ctx context.Context, word string, results chan<- lib.Result) error {
+	return d.RunBase(ctx, d.options.URL, word, results)
+}
+
+// RunBase 对base目录执行一次Run,开启递归时会记录发现的目录
+func (d *GobusterDir) RunBase(ctx context.Context, base string, word string, results chan<- lib.Result) error {
 	suffix := ""
 	if d.options.UseSlash {
 		suffix = "/"
 	}
+	//结果中的路径始终相对于最初的url
+	prefix := strings.TrimPrefix(base, d.options.URL)
 
 	urlsToCheck := make(map[string]string)
-	entity := fmt.Sprintf("%s%s", word, suffix)          //相对路径
-	dirUrl := fmt.Sprintf("%s%s", d.options.URL, entity) //与url拼接成绝对路径
+	entity := fmt.Sprintf("%s%s", word, suffix) //相对路径
+	dirUrl := fmt.Sprintf("%s%s", base, entity) //与url拼接成绝对路径
 	urlsToCheck[entity] = dirUrl
 
 	if d.options.DiscoverBackup { //是否找备份文件,添加到待扫描的列表中
 		for _, u := range getBackupFilenames(word) {
-			url := fmt.Sprintf("%s%s", d.options.URL, u)
+			url := fmt.Sprintf("%s%s", base, u)
 			urlsToCheck[u] = url
 		}
 	}
 	for ext := range d.options.ExtensionsParsed.Set {
 		filename := fmt.Sprintf("%s.%s", word, ext)
-		url := fmt.Sprintf("%s%s", d.options.URL, filename)
+		url := fmt.Sprintf("%s%s", base, filename)
 		urlsToCheck[filename] = url
 		if d.options.DiscoverBackup {
 			for _, u := range getBackupFilenames(filename) {
-				url2 := fmt.Sprintf("%s%s", d.options.URL, u)
+				url2 := fmt.Sprintf("%s%s", base, u)
 				urlsToCheck[u] = url2
 			}
 		}
 	}
 
-	for entity, url := range urlsToCheck {
+	wildcards := d.getWildcards(base)
+	for path, url := range urlsToCheck {
 		//发起http请求 获取结果
-		statusCode, size, header, _, err := d.http.Request(ctx, url, lib.RequestOptions{})
+		start := time.Now()
+		statusCode, size, header, body, err := d.http.Request(ctx, url, lib.RequestOptions{ReturnBody: d.matcher.NeedsBody() || len(wildcards) > 0})
+		duration := time.Since(start)
 		if err != nil {
 			return err
 		}
 
 		if statusCode != nil {
-			resultStatus := false
+			resultStatus, err := d.isHit(*statusCode, size, header, body)
+			if err != nil {
+				return err
+			}
+			//与通配的响应相同时视为不存在
+			if resultStatus && isWildcard(wildcards, path, *statusCode, size, header, body) {
+				resultStatus = false
+			}
 
-			//判断需要过滤的状态码
-			if d.options.StatusCodesBlacklistParsed.Length() > 0 {
-				if !d.options.StatusCodesBlacklistParsed.Contains(*statusCode) {
-					resultStatus = true
-				}
-			} else if d.options.StatusCodesParsed.Length() > 0 {
-				if d.options.StatusCodesParsed.Contains(*statusCode) {
-					resultStatus = true
+			//只对单词本身的请求判断是否为目录,拓展名和备份文件不会是目录
+			var dirErr error
+			if d.options.Recursive && resultStatus && path == entity {
+				if u, ok := directoryURL(url, *statusCode, header); ok {
+					d.addBase(base, u)
+				} else if !strings.HasSuffix(url, "/") {
+					dirErr = d.checkDirectory(ctx, base, path, wildcards)
 				}
-			} else {
-				return fmt.Errorf("StatusCodes and StatusCodesBlacklist are both not set which should not happen")
 			}
+
+			//对发现的路径尝试其他的method
+			var methods []string
+			var probeErr error
+			if resultStatus && len(d.options.ProbeMethods) > 0 {
+				methods, probeErr = d.probeMethods(ctx, url)
+			}
+
 			//构建结果返回
-			if (resultStatus && !helper.SliceContains(d.options.ExcludeLength, int(size))) || d.globalopts.Verbose {
+			if resultStatus || d.globalopts.Verbose {
 				results <- Result{
 					URL:        d.options.URL,
-					Path:       entity,
-					Verbose:    d.globalopts.Verbose,
-					Expanded:   d.options.Expanded,
-					NoStatus:   d.options.NoStatus,
-					HideLength: d.options.HideLength,
+					Path:       prefix + path,
+					Word:       word,
 					Found:      resultStatus,
 					Header:     header,
 					StatusCode: *statusCode,
 					Size:       size,
+					Duration:   duration,
+					Methods:    methods,
 				}
 			}
+			if dirErr != nil {
+				return dirErr
+			}
+			if probeErr != nil {
+				return probeErr
+			}
 		}
 
 	}
 	return nil
 }
 
+// ExtraRequests 只对命中的结果进行method探测以及目录确认,这些请求数无法预先计算,单独统计
+func (d *GobusterDir) ExtraRequests() int {
+	return int(atomic.LoadInt64(&d.extraRequests))
+}
+
+// checkDirectory 命中的路径没有跳转到加/的地址时,请求path加/确认是否为目录(200/403)
+func (d *GobusterDir) checkDirectory(ctx context.Context, base, path string, wildcards []wildcardFingerprint) error {
+	atomic.AddInt64(&d.extraRequests, 1)
+	dirURL := fmt.Sprintf("%s%s/", base, path)
+	statusCode, size, header, body, err := d.http.Request(ctx, dirURL, lib.RequestOptions{ReturnBody: len(wildcards) > 0})
+	if err != nil {
+		return err
+	}
+	if statusCode == nil || isWildcard(wildcards, path+"/", *statusCode, size, header, body) {
+		return nil
+	}
+	if u, ok := directoryURL(dirURL, *statusCode, header); ok {
+		d.addBase(base, u)
+	}
+	return nil
+}
+
+// probeMethods 使用ProbeMethods中的每个method请求url,返回允许的method:
+// 探测成功的method附带状态码,只在响应的Allow头中列出的method标记为advertised
+// 4xx(包括需要认证和拒绝访问)以及501视为不允许;出错的method会被跳过,返回第一个错误
+func (d *GobusterDir) probeMethods(ctx context.Context, url string) ([]string, error) {
+	var probed []string
+	var advertised []string
+	var firstErr error
+	for _, method := range d.options.ProbeMethods {
+		atomic.AddInt64(&d.extraRequests, 1)
+		statusCode, _, header, _, err := d.http.Request(ctx, url, lib.RequestOptions{Method: method})
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s %s: %w", method, url, err)
+			}
+			continue
+		}
+		if statusCode == nil {
+			break
+		}
+		//OPTIONS以及405的响应中会列出允许的method,每个响应的都需要保留
+		advertised = append(advertised, parseAllow(header)...)
+		if *statusCode >= 400 && *statusCode < 500 || *statusCode == http.StatusNotImplemented {
+			continue
+		}
+		probed = append(probed, fmt.Sprintf("%s (%d)", method, *statusCode))
+	}
+	return mergeMethods(probed, advertised), firstErr
+}
+
+// parseAllow 解析Allow头(可能出现多次),返回大写的method
+func parseAllow(header http.Header) []string {
+	var methods []string
+	for _, v := range header.Values("Allow") {
+		for _, m := range strings.Split(v, ",") {
+			if m = strings.ToUpper(strings.TrimSpace(m)); m != "" {
+				methods = append(methods, m)
+			}
+		}
+	}
+	return methods
+}
+
+// mergeMethods 合并探测成功的method("METHOD (状态码)")和Allow头中的method并去重,
+// 已经探测成功的method不再重复标记为advertised
+func mergeMethods(probed, advertised []string) []string {
+	seen := make(map[string]bool)
+	var methods []string
+	for _, p := range probed {
+		m := strings.ToUpper(p[:strings.Index(p, " ")])
+		if !seen[m] {
+			seen[m] = true
+			methods = append(methods, p)
+		}
+	}
+	for _, m := range advertised {
+		if !seen[m] {
+			seen[m] = true
+			methods = append(methods, m+" (advertised)")
+		}
+	}
+	return methods
+}
+
 func (d *GobusterDir) GetConfigString() (string, error) {
 	var buf bytes.Buffer
 	bw := bufio.NewWriter(&buf)
@@ -278,25 +520,23 @@ func (d *GobusterDir) GetConfigString() (string, error) {
 		}
 	}
 
-	if o.Proxy != "" {
-		if _, err := fmt.Fprintf(tw, "[+] Proxy:\t%s\n", o.Proxy); err != nil {
+	if m := o.Matchers.Stringify(); m != "" {
+		if _, err := fmt.Fprintf(tw, "[+] Matchers:\t%s\n", m); err != nil {
 			return "", err
 		}
 	}
 
-	if o.Cookies != "" {
-		if _, err := fmt.Fprintf(tw, "[+] Cookies:\t%s\n", o.Cookies); err != nil {
-			return "", err
-		}
+	if err := o.WriteHTTPConfig(tw); err != nil {
+		return "", err
 	}
 
-	if o.UserAgent != "" {
-		if _, err := fmt.Fprintf(tw, "[+] User Agent:\t%s\n", o.UserAgent); err != nil {
+	if o.Cookies != "" {
+		if _, err := fmt.Fprintf(tw, "[+] Cookies:\t%s\n", o.Cookies); err != nil {
 			return "", err
 		}
 	}
 
-	if o.HideLength {
+	if d.globalopts.HideLength {
 		if _, err := fmt.Fprintf(tw, "[+] Show length:\tfalse\n"); err != nil {
 			return "", err
 		}
@@ -326,13 +566,25 @@ func (d *GobusterDir) GetConfigString() (string, error) {
 		}
 	}
 
-	if o.Expanded {
+	if o.Recursive {
+		if _, err := fmt.Fprintf(tw, "[+] Recursive:\tmax depth %d\n", o.MaxDepth); err != nil {
+			return "", err
+		}
+	}
+
+	if len(o.ProbeMethods) > 0 {
+		if _, err := fmt.Fprintf(tw, "[+] Probe Methods:\t%s\n", strings.Join(o.ProbeMethods, ",")); err != nil {
+			return "", err
+		}
+	}
+
+	if d.globalopts.Expanded {
 		if _, err := fmt.Fprintf(tw, "[+] Expanded:\ttrue\n"); err != nil {
 			return "", err
 		}
 	}
 
-	if o.NoStatus {
+	if d.globalopts.NoStatus {
 		if _, err := fmt.Fprintf(tw, "[+] No status:\ttrue\n"); err != nil {
 			return "", err
 		}
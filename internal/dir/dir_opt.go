@@ -11,11 +11,12 @@ type OptionsDir struct {
 	StatusCodesBlacklist       string
 	StatusCodesBlacklistParsed lib.IntSet
 	UseSlash                   bool
-	HideLength                 bool
-	Expanded                   bool
-	NoStatus                   bool
 	DiscoverBackup             bool
 	ExcludeLength              []int
+	Matchers                   lib.MatcherOptions
+	Recursive                  bool
+	MaxDepth                   int
+	ProbeMethods               []string //对每个发现的路径尝试的method
 }
 
 func NewOptionsDir() *OptionsDir {
@@ -0,0 +1,114 @@
+package dir
+
+import (
+	"buster/lib"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newTestPlugin 对url生成只接受200的插件,setup不为nil时在创建之前修改配置
+func newTestPlugin(t *testing.T, url string, setup func(*OptionsDir)) *GobusterDir {
+	t.Helper()
+	globalopts := lib.NewOptions()
+	opts := NewOptionsDir()
+	opts.URL = url
+	opts.Timeout = time.Second
+	opts.StatusCodesParsed.Add(http.StatusOK)
+	if setup != nil {
+		setup(opts)
+	}
+	d, err := NewGobusterDir(globalopts, opts)
+	if err != nil {
+		t.Fatalf("NewGobusterDir: %v", err)
+	}
+	if err := d.PreRun(context.Background()); err != nil {
+		t.Fatalf("PreRun: %v", err)
+	}
+	return d
+}
+
+// runWord 执行一次Run并返回所有的结果
+func runWord(t *testing.T, d *GobusterDir, word string) []Result {
+	t.Helper()
+	results := make(chan lib.Result, 10)
+	if err := d.Run(context.Background(), word, results); err != nil {
+		t.Fatalf("Run(%s): %v", word, err)
+	}
+	close(results)
+	var ret []Result
+	for r := range results {
+		ret = append(ret, r.(Result))
+	}
+	return ret
+}
+
+func TestProbeMethods(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/admin" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch r.Method {
+		case http.MethodPut:
+			w.Header().Set("Allow", "GET, PUT")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		case http.MethodPost:
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodOptions:
+			//大小写不同以及重复的method
+			w.Header().Add("Allow", "get, post,OPTIONS")
+			w.Header().Add("Allow", " head ")
+		}
+	}))
+	defer ts.Close()
+	d := newTestPlugin(t, ts.URL, func(o *OptionsDir) {
+		o.ProbeMethods = []string{"PUT", "POST", "OPTIONS"}
+	})
+
+	results := runWord(t, d, "admin")
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1", len(results))
+	}
+	want := []string{"POST (201)", "OPTIONS (200)", "GET (advertised)", "PUT (advertised)", "HEAD (advertised)"}
+	if !reflect.DeepEqual(results[0].Methods, want) {
+		t.Errorf("got methods %q, want %q", results[0].Methods, want)
+	}
+	if n := d.ExtraRequests(); n != 3 {
+		t.Errorf("got %d extra requests, want 3", n)
+	}
+}
+
+func TestRecursiveWithoutRedirect(t *testing.T) {
+	//admin不跳转,admin/返回200;file/不存在;old跳转到old/
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/admin", "/admin/", "/file", "/old/":
+		case "/old":
+			http.Redirect(w, r, "/old/", http.StatusMovedPermanently)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	d := newTestPlugin(t, ts.URL, func(o *OptionsDir) {
+		o.StatusCodesParsed.Add(http.StatusMovedPermanently)
+		o.Recursive = true
+		o.MaxDepth = 2
+	})
+
+	for _, word := range []string{"admin", "file", "old"} {
+		runWord(t, d, word)
+	}
+	want := []string{ts.URL + "/admin/", ts.URL + "/old/"}
+	if bases := d.NewBases(); !reflect.DeepEqual(bases, want) {
+		t.Errorf("got bases %q, want %q", bases, want)
+	}
+	//跳转的目录不需要额外确认
+	if n := d.ExtraRequests(); n != 2 {
+		t.Errorf("got %d extra requests, want 2", n)
+	}
+}
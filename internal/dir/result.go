@@ -1,72 +1,37 @@
 package dir
 
 import (
-	"bytes"
-	"fmt"
+	"buster/lib"
 	"net/http"
+	"time"
 )
 
+// PluginName 结果中标记的插件名称
+const PluginName = "dir"
+
 type Result struct {
-	URL, Path                                      string
-	Verbose, Expanded, NoStatus, HideLength, Found bool
-	Header                                         http.Header
-	StatusCode                                     int
-	Size                                           int64
+	URL, Path, Word string
+	Found           bool
+	Header          http.Header
+	StatusCode      int
+	Size            int64
+	Duration        time.Duration
+	Methods         []string //允许的method及其状态码,或只在Allow头中列出(advertised)
 }
 
-// ResulToString 实现result接口,将结果转换为字符串
-func (r Result) ResulToString() (string, error) {
-	buf := &bytes.Buffer{}
-
-	// Prefix if we're in verbose mode
-	if r.Verbose {
-		if r.Found {
-			if _, err := fmt.Fprintf(buf, "Found: "); err != nil {
-				return "", err
-			}
-		} else {
-			if _, err := fmt.Fprintf(buf, "Missed: "); err != nil {
-				return "", err
-			}
-		}
-	}
-
-	//是否打印完整url 或者只是相对路径
-	if r.Expanded {
-		if _, err := fmt.Fprintf(buf, "%s", r.URL); err != nil {
-			return "", err
-		}
-	} else {
-		if _, err := fmt.Fprintf(buf, "/"); err != nil {
-			return "", err
-		}
-	}
-	if _, err := fmt.Fprintf(buf, "%-20s", r.Path); err != nil {
-		return "", err
-	}
-	if !r.NoStatus {
-		if _, err := fmt.Fprintf(buf, " (Status: %d)", r.StatusCode); err != nil {
-			return "", err
-		}
-	}
-
-	if !r.HideLength {
-		if _, err := fmt.Fprintf(buf, " [Size: %d]", r.Size); err != nil {
-			return "", err
-		}
-	}
-
-	//location一般是301重定向时会被写入
-	location := r.Header.Get("Location")
-	if location != "" {
-		if _, err := fmt.Fprintf(buf, "[--> %s]", location); err != nil {
-			return "", err
-		}
-	}
-	if _, err := fmt.Fprintf(buf, "\n"); err != nil {
-		return "", err
+// Fields 实现result接口,URL为最初的目标
+func (r Result) Fields() lib.ResultFields {
+	return lib.ResultFields{
+		Plugin:     PluginName,
+		Target:     r.URL,
+		Found:      r.Found,
+		URL:        r.URL + r.Path,
+		Path:       "/" + r.Path,
+		Word:       r.Word,
+		StatusCode: r.StatusCode,
+		Size:       r.Size,
+		Header:     r.Header,
+		Duration:   r.Duration,
+		Methods:    r.Methods,
 	}
-
-	s := buf.String()
-	return s, nil
 }
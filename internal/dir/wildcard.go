@@ -0,0 +1,111 @@
+package dir
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+)
+
+const (
+	wildcardPlaceholder   = "{WORD}"
+	wildcardSimilarity    = 0.9  //body的单词集合相似度达到该值时认为是同一个页面
+	wildcardSizeTolerance = 0.02 //大小允许的浮动比例
+	wildcardMinTolerance  = 16   //大小允许的最小浮动字节数
+)
+
+// wildcardProbe 去除了路径回显后的响应特征,用于和通配的指纹进行比较
+type wildcardProbe struct {
+	statusCode int
+	size       int64
+	location   string
+	tokens     map[string]struct{}
+}
+
+// newWildcardProbe 将body和跳转地址中出现的请求名称去除,避免不同长度的路径影响比较
+func newWildcardProbe(name string, statusCode int, size int64, header http.Header, body []byte) wildcardProbe {
+	p := wildcardProbe{statusCode: statusCode, size: size}
+	if name != "" && body != nil {
+		n := bytes.Count(body, []byte(name))
+		p.size -= int64(n * len(name))
+		body = bytes.ReplaceAll(body, []byte(name), nil)
+	}
+	if body != nil {
+		p.tokens = make(map[string]struct{})
+		for _, t := range bytes.Fields(body) {
+			p.tokens[string(t)] = struct{}{}
+		}
+	}
+	p.location = header.Get("Location")
+	if name != "" {
+		p.location = strings.ReplaceAll(p.location, name, wildcardPlaceholder)
+	}
+	return p
+}
+
+// wildcardFingerprint 不存在的路径返回的响应(如soft-404页面)的指纹
+type wildcardFingerprint struct {
+	statusCode       int
+	location         string
+	minSize, maxSize int64
+	tokens           map[string]struct{}
+}
+
+// newWildcardFingerprints 将状态码和跳转地址相同的探测结果合并为一个指纹
+func newWildcardFingerprints(probes []wildcardProbe) []wildcardFingerprint {
+	var fps []wildcardFingerprint
+NEXT:
+	for _, p := range probes {
+		for i := range fps {
+			f := &fps[i]
+			if f.statusCode == p.statusCode && f.location == p.location {
+				if p.size < f.minSize {
+					f.minSize = p.size
+				}
+				if p.size > f.maxSize {
+					f.maxSize = p.size
+				}
+				continue NEXT
+			}
+		}
+		fps = append(fps, wildcardFingerprint{
+			statusCode: p.statusCode,
+			location:   p.location,
+			minSize:    p.size,
+			maxSize:    p.size,
+			tokens:     p.tokens,
+		})
+	}
+	return fps
+}
+
+// matches 判断响应是否与指纹相同
+func (f *wildcardFingerprint) matches(p wildcardProbe) bool {
+	if f.statusCode != p.statusCode || f.location != p.location {
+		return false
+	}
+	tolerance := int64(float64(f.maxSize) * wildcardSizeTolerance)
+	if tolerance < wildcardMinTolerance {
+		tolerance = wildcardMinTolerance
+	}
+	if p.size < f.minSize-tolerance || p.size > f.maxSize+tolerance {
+		return false
+	}
+	if f.tokens != nil && p.tokens != nil && similarity(f.tokens, p.tokens) < wildcardSimilarity {
+		return false
+	}
+	return true
+}
+
+// similarity 两个单词集合的Jaccard相似度
+func similarity(a, b map[string]struct{}) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	common := 0
+	for t := range a {
+		if _, ok := b[t]; ok {
+			common++
+		}
+	}
+	return float64(common) / float64(len(a)+len(b)-common)
+}
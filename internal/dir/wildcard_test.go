@@ -0,0 +1,89 @@
+package dir
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// page 回显请求名称的soft-404页面,padding用于调整大小
+func page(name string, padding int) string {
+	return "<html><body>The page " + name + " could not be found on this server</body></html>" + strings.Repeat(" ", padding)
+}
+
+// probe 生成响应的特征,body为空字符串时视为没有读取body
+func probe(name string, statusCode int, body, location string) wildcardProbe {
+	header := http.Header{}
+	if location != "" {
+		header.Set("Location", location)
+	}
+	var b []byte
+	if body != "" {
+		b = []byte(body)
+	}
+	return newWildcardProbe(name, statusCode, int64(len(body)), header, b)
+}
+
+func TestWildcardMatches(t *testing.T) {
+	//不同长度的随机名称得到的soft-404页面,大小约为2000字节,允许约40字节的浮动
+	large := newWildcardFingerprints([]wildcardProbe{
+		probe("0d6c", http.StatusOK, page("0d6c", 2000), ""),
+		probe("0d6c7f0e-5a1b-4c4e-9a43-2b1e4f0a7c11", http.StatusOK, page("0d6c7f0e-5a1b-4c4e-9a43-2b1e4f0a7c11", 2000), ""),
+	})
+	//很小的页面使用最小的浮动字节数
+	small := newWildcardFingerprints([]wildcardProbe{probe("x1", http.StatusOK, "nope", "")})
+	//跳转地址中回显请求名称
+	redirect := newWildcardFingerprints([]wildcardProbe{
+		probe("0d6c", http.StatusFound, "", "/login?next=/0d6c"),
+	})
+
+	tests := []struct {
+		name string
+		fps  []wildcardFingerprint
+		p    wildcardProbe
+		want bool
+	}{
+		{"echoed name", large, probe("admin", http.StatusOK, page("admin", 2000), ""), true},
+		{"size within tolerance", large, probe("admin", http.StatusOK, page("admin", 2030), ""), true},
+		{"size outside tolerance", large, probe("admin", http.StatusOK, page("admin", 2100), ""), false},
+		{"smaller than the fingerprint", large, probe("admin", http.StatusOK, page("admin", 1900), ""), false},
+		{"different status", large, probe("admin", http.StatusForbidden, page("admin", 2000), ""), false},
+		{"different content of the same size", large,
+			probe("admin", http.StatusOK, "<html><head>Admin panel of this great site, welcome back user</head></html>"+strings.Repeat(" ", 2000), ""), false},
+		//没有读取body时只比较大小
+		{"body not read", large, newWildcardProbe("admin", http.StatusOK, int64(len(page("admin", 2000))), http.Header{}, nil), true},
+		{"small page within minimum tolerance", small, probe("admin", http.StatusOK, "nope"+strings.Repeat(" ", 10), ""), true},
+		{"small page outside minimum tolerance", small, probe("admin", http.StatusOK, "nope"+strings.Repeat(" ", 20), ""), false},
+		{"same redirect", redirect, probe("admin", http.StatusFound, "", "/login?next=/admin"), true},
+		{"different redirect", redirect, probe("admin", http.StatusFound, "", "/admin/"), false},
+	}
+	for _, tt := range tests {
+		got := false
+		for i := range tt.fps {
+			if tt.fps[i].matches(tt.p) {
+				got = true
+			}
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWildcardFingerprintsMerge(t *testing.T) {
+	//状态码和跳转地址相同的探测合并为一个指纹,记录大小的范围
+	fps := newWildcardFingerprints([]wildcardProbe{
+		probe("q7z1", http.StatusOK, page("q7z1", 100), ""),
+		probe("w8k2", http.StatusOK, page("w8k2", 300), ""),
+		probe("e9v3", http.StatusFound, "", "/e9v3/"),
+	})
+	if len(fps) != 2 {
+		t.Fatalf("got %d fingerprints, want 2", len(fps))
+	}
+	if fps[0].maxSize-fps[0].minSize != 200 {
+		t.Errorf("got size range %d-%d, want a range of 200", fps[0].minSize, fps[0].maxSize)
+	}
+	if fps[1].location != "/"+wildcardPlaceholder+"/" {
+		t.Errorf("got location %q, want the name replaced", fps[1].location)
+	}
+}
@@ -0,0 +1,236 @@
+package dns
+
+import (
+	"bufio"
+	"buster/lib"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"net"
+	"strings"
+	"text/tabwriter"
+)
+
+// ErrWildcard 泛解析时返回的错误
+type ErrWildcard struct {
+	wildcardIps lib.StringSet
+}
+
+func (e *ErrWildcard) Error() string {
+	return fmt.Sprintf("the DNS Server returned the same IP for every domain. IP address(es) returned: %s", e.wildcardIps.Stringify())
+}
+
+// GobusterDNS dns模式的核心实现,实现plugin接口,对每个单词拼接域名后进行解析
+type GobusterDNS struct {
+	resolver    *net.Resolver
+	globalopts  *lib.Options
+	options     *OptionsDNS
+	isWildcard  bool
+	wildcardIps lib.StringSet
+}
+
+// NewGobusterDNS 根据全局配置和dns配置生成GobusterDNS(实现了plugin接口)
+func NewGobusterDNS(globalopts *lib.Options, opts *OptionsDNS) (*GobusterDNS, error) {
+	if globalopts == nil {
+		return nil, fmt.Errorf("please provide valid global options")
+	}
+
+	if opts == nil {
+		return nil, fmt.Errorf("please provide valid plugin options")
+	}
+
+	//默认使用系统的解析器,指定了resolver时所有的查询都发往该地址
+	resolver := net.DefaultResolver
+	if opts.Resolver != "" {
+		resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+				d := net.Dialer{
+					Timeout: opts.Timeout,
+				}
+				return d.DialContext(ctx, network, opts.Resolver)
+			},
+		}
+	}
+
+	g := GobusterDNS{
+		options:     opts,
+		globalopts:  globalopts,
+		wildcardIps: lib.NewStringSet(),
+		resolver:    resolver,
+	}
+	return &g, nil
+}
+
+func (d *GobusterDNS) Name() string {
+	return "DNS enumeration"
+}
+
+// RequestPerRun 每个单词只进行一次解析
+func (d *GobusterDNS) RequestPerRun() int {
+	return 1
+}
+
+// PreRun 解析一个随机的子域名,判断是否存在泛解析;解析器无法正常响应时返回错误,避免关闭泛解析检测
+func (d *GobusterDNS) PreRun(ctx context.Context) error {
+	guid := uuid.New()
+	domain := fmt.Sprintf("%s.%s", guid, d.options.Domain)
+	wildcardIps, err := d.dnsLookup(ctx, domain)
+	if err != nil && !isNotFound(err) {
+		return fmt.Errorf("unable to resolve %s: %w", domain, err)
+	}
+	if err == nil {
+		d.isWildcard = true
+		d.wildcardIps.AddRange(wildcardIps)
+		if !d.options.WildcardForced {
+			return &ErrWildcard{wildcardIps: d.wildcardIps}
+		}
+	}
+	return nil
+}
+
+// Run 解析word.domain,解析成功(且不是泛解析的结果)时写入结果;
+// 只有域名不存在视为未命中,超时、SERVFAIL以及被拒绝等错误返回给引擎
+func (d *GobusterDNS) Run(ctx context.Context, word string, results chan<- lib.Result) error {
+	subdomain := fmt.Sprintf("%s.%s", word, d.options.Domain)
+	ips, err := d.dnsLookup(ctx, subdomain)
+	if err != nil && !isNotFound(err) {
+		return err
+	}
+	if err == nil {
+		if !d.isWildcard || !d.wildcardIps.ContainsAny(ips) {
+			result := Result{
+				Domain:    d.options.Domain,
+				Subdomain: subdomain,
+				Word:      word,
+				Found:     true,
+			}
+			if d.options.ShowIPs {
+				result.IPs = ips
+			}
+			if d.options.ShowCNAME {
+				cname, err := d.dnsLookupCname(ctx, subdomain)
+				if err == nil {
+					result.CNAME = cname
+				}
+			}
+			results <- result
+		}
+	} else if d.globalopts.Verbose {
+		results <- Result{
+			Domain:    d.options.Domain,
+			Subdomain: subdomain,
+			Word:      word,
+			Found:     false,
+		}
+	}
+	return nil
+}
+
+func (d *GobusterDNS) GetConfigString() (string, error) {
+	var buf bytes.Buffer
+	bw := bufio.NewWriter(&buf)
+	tw := tabwriter.NewWriter(bw, 0, 5, 3, ' ', 0)
+	o := d.options
+
+	if _, err := fmt.Fprintf(tw, "[+] Domain:\t%s\n", o.Domain); err != nil {
+		return "", err
+	}
+
+	if _, err := fmt.Fprintf(tw, "[+] Threads:\t%d\n", d.globalopts.Threads); err != nil {
+		return "", err
+	}
+
+	if d.globalopts.Delay > 0 {
+		if _, err := fmt.Fprintf(tw, "[+] Delay:\t%s\n", d.globalopts.Delay); err != nil {
+			return "", err
+		}
+	}
+
+	if o.Resolver != "" {
+		if _, err := fmt.Fprintf(tw, "[+] Resolver:\t%s\n", o.Resolver); err != nil {
+			return "", err
+		}
+	}
+
+	if o.ShowCNAME {
+		if _, err := fmt.Fprintf(tw, "[+] Show CNAME:\ttrue\n"); err != nil {
+			return "", err
+		}
+	}
+
+	if o.ShowIPs {
+		if _, err := fmt.Fprintf(tw, "[+] Show IPs:\ttrue\n"); err != nil {
+			return "", err
+		}
+	}
+
+	if o.WildcardForced {
+		if _, err := fmt.Fprintf(tw, "[+] Wildcard forced:\ttrue\n"); err != nil {
+			return "", err
+		}
+	}
+
+	if _, err := fmt.Fprintf(tw, "[+] Timeout:\t%s\n", o.Timeout.String()); err != nil {
+		return "", err
+	}
+
+	wordlist := "stdin (pipe)"
+	if d.globalopts.Wordlist != "-" {
+		wordlist = d.globalopts.Wordlist
+	}
+	if _, err := fmt.Fprintf(tw, "[+] Wordlist:\t%s\n", wordlist); err != nil {
+		return "", err
+	}
+
+	if d.globalopts.PatternFile != "" {
+		if _, err := fmt.Fprintf(tw, "[+] Patterns:\t%s (%d entries)\n", d.globalopts.PatternFile, len(d.globalopts.Patterns)); err != nil {
+			return "", err
+		}
+	}
+
+	if d.globalopts.Verbose {
+		if _, err := fmt.Fprintf(tw, "[+] Verbose:\ttrue\n"); err != nil {
+			return "", err
+		}
+	}
+
+	if err := tw.Flush(); err != nil {
+		return "", fmt.Errorf("error on tostring: %w", err)
+	}
+
+	if err := bw.Flush(); err != nil {
+		return "", fmt.Errorf("error on tostring: %w", err)
+	}
+
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// isNotFound 判断解析的错误是否为域名不存在
+func isNotFound(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
+
+func (d *GobusterDNS) dnsLookup(ctx context.Context, domain string) ([]string, error) {
+	ctx2, cancel := context.WithTimeout(ctx, d.options.Timeout)
+	defer cancel()
+	return d.resolver.LookupHost(ctx2, domain)
+}
+
+func (d *GobusterDNS) dnsLookupCname(ctx context.Context, domain string) (string, error) {
+	ctx2, cancel := context.WithTimeout(ctx, d.options.Timeout)
+	defer cancel()
+	cname, err := d.resolver.LookupCNAME(ctx2, domain)
+	if err != nil {
+		return "", err
+	}
+	//去掉末尾的.,与域名本身相同时说明没有CNAME记录
+	cname = strings.TrimSuffix(cname, ".")
+	if strings.EqualFold(cname, domain) {
+		return "", nil
+	}
+	return cname, nil
+}
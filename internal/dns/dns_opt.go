@@ -0,0 +1,17 @@
+package dns
+
+import "time"
+
+// OptionsDNS dns模式的配置
+type OptionsDNS struct {
+	Domain         string
+	ShowIPs        bool
+	ShowCNAME      bool
+	WildcardForced bool
+	Resolver       string
+	Timeout        time.Duration
+}
+
+func NewOptionsDNS() *OptionsDNS {
+	return &OptionsDNS{}
+}
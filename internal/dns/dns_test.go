@@ -0,0 +1,268 @@
+package dns
+
+import (
+	"buster/lib"
+	"context"
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 测试用的dns记录类型和响应码
+const (
+	typeA     = 1
+	typeCNAME = 5
+
+	rcodeNXDomain = 3
+	rcodeRefused  = 5
+)
+
+// record 测试服务器中的一条记录,cname不为空时先返回CNAME再返回目标的A记录
+type record struct {
+	ip    string
+	cname string
+}
+
+// stubServer 进程内的dns服务器,只处理单个问题的查询
+type stubServer struct {
+	conn     net.PacketConn
+	records  map[string]record
+	refused  map[string]bool
+	silent   map[string]bool //不响应的查询
+	wildcard string          //不为空时,域名下所有不存在的子域名都解析到该地址
+	domain   string
+}
+
+// newStubServer 启动服务器,setup不为nil时在开始响应之前修改服务器的配置
+func newStubServer(t *testing.T, domain string, records map[string]record, setup func(*stubServer)) *stubServer {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("could not listen: %v", err)
+	}
+	s := &stubServer{conn: conn, records: records, refused: map[string]bool{}, silent: map[string]bool{}, domain: domain}
+	if setup != nil {
+		setup(s)
+	}
+	t.Cleanup(func() { conn.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *stubServer) addr() string {
+	return s.conn.LocalAddr().String()
+}
+
+func (s *stubServer) serve() {
+	buf := make([]byte, 512)
+	for {
+		n, from, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		if resp := s.answer(buf[:n]); resp != nil {
+			_, _ = s.conn.WriteTo(resp, from)
+		}
+	}
+}
+
+// answer 根据查询构造响应,问题部分原样返回,回答中的名称使用指向问题的压缩指针
+func (s *stubServer) answer(q []byte) []byte {
+	if len(q) < 12 {
+		return nil
+	}
+	//读取问题中的域名
+	var labels []string
+	i := 12
+	for i < len(q) && q[i] != 0 {
+		l := int(q[i])
+		if i+1+l > len(q) {
+			return nil
+		}
+		labels = append(labels, string(q[i+1:i+1+l]))
+		i += 1 + l
+	}
+	end := i + 5
+	if end > len(q) {
+		return nil
+	}
+	qtype := binary.BigEndian.Uint16(q[i+1:])
+	name := strings.ToLower(strings.Join(labels, "."))
+
+	resp := make([]byte, 12, 512)
+	copy(resp, q[:2])
+	resp = append(resp, q[12:end]...)
+	binary.BigEndian.PutUint16(resp[4:], 1)
+
+	if s.silent[name] {
+		return nil
+	}
+
+	var rcode, count uint16
+	rec, ok := s.records[name]
+	switch {
+	case s.refused[name]:
+		rcode = rcodeRefused
+	case ok:
+	case s.wildcard != "" && strings.HasSuffix(name, "."+s.domain):
+		rec, ok = record{ip: s.wildcard}, true
+	default:
+		rcode = rcodeNXDomain
+	}
+	if ok {
+		target := []byte{0xc0, 12}
+		if rec.cname != "" {
+			rdata := encodeName(rec.cname)
+			resp = appendRR(resp, target, typeCNAME, rdata)
+			count++
+			target = rdata
+			rec = s.records[rec.cname]
+		}
+		if qtype == typeA && rec.ip != "" {
+			resp = appendRR(resp, target, typeA, net.ParseIP(rec.ip).To4())
+			count++
+		}
+	}
+	binary.BigEndian.PutUint16(resp[2:], 0x8180|rcode)
+	binary.BigEndian.PutUint16(resp[6:], count)
+	return resp
+}
+
+func encodeName(name string) []byte {
+	var b []byte
+	for _, l := range strings.Split(name, ".") {
+		b = append(b, byte(len(l)))
+		b = append(b, l...)
+	}
+	return append(b, 0)
+}
+
+func appendRR(b, name []byte, rrtype uint16, rdata []byte) []byte {
+	h := make([]byte, 10)
+	binary.BigEndian.PutUint16(h, rrtype)
+	binary.BigEndian.PutUint16(h[2:], 1)
+	binary.BigEndian.PutUint32(h[4:], 60)
+	binary.BigEndian.PutUint16(h[8:], uint16(len(rdata)))
+	b = append(b, name...)
+	b = append(b, h...)
+	return append(b, rdata...)
+}
+
+func newTestPlugin(t *testing.T, s *stubServer, opts *OptionsDNS) *GobusterDNS {
+	t.Helper()
+	globalopts := lib.NewOptions()
+	globalopts.Verbose = true
+	opts.Domain = s.domain
+	opts.Resolver = s.addr()
+	opts.Timeout = time.Second
+	d, err := NewGobusterDNS(globalopts, opts)
+	if err != nil {
+		t.Fatalf("NewGobusterDNS: %v", err)
+	}
+	return d
+}
+
+func run(t *testing.T, d *GobusterDNS, word string) Result {
+	t.Helper()
+	results := make(chan lib.Result, 1)
+	if err := d.Run(context.Background(), word, results); err != nil {
+		t.Fatalf("Run(%s): %v", word, err)
+	}
+	select {
+	case r := <-results:
+		return r.(Result)
+	default:
+		t.Fatalf("Run(%s) returned no result", word)
+		return Result{}
+	}
+}
+
+func TestRun(t *testing.T) {
+	s := newStubServer(t, "buster.test", map[string]record{
+		"www.buster.test":   {ip: "10.0.0.1"},
+		"alias.buster.test": {cname: "www.buster.test"},
+	}, nil)
+	d := newTestPlugin(t, s, &OptionsDNS{ShowIPs: true, ShowCNAME: true})
+	if err := d.PreRun(context.Background()); err != nil {
+		t.Fatalf("PreRun: %v", err)
+	}
+
+	r := run(t, d, "www")
+	if !r.Found || r.Subdomain != "www.buster.test" {
+		t.Fatalf("www: got %+v, want found", r)
+	}
+	if len(r.IPs) != 1 || r.IPs[0] != "10.0.0.1" {
+		t.Errorf("www: got IPs %v, want [10.0.0.1]", r.IPs)
+	}
+	if r.CNAME != "" {
+		t.Errorf("www: got CNAME %q, want none", r.CNAME)
+	}
+
+	r = run(t, d, "alias")
+	if !r.Found || r.CNAME != "www.buster.test" {
+		t.Errorf("alias: got %+v, want found with CNAME www.buster.test", r)
+	}
+
+	if r := run(t, d, "missing"); r.Found {
+		t.Errorf("missing: got %+v, want not found", r)
+	}
+}
+
+func TestRunResolverError(t *testing.T) {
+	//被拒绝以及不响应的查询需要作为错误返回,不能视为不存在
+	s := newStubServer(t, "buster.test", nil, func(s *stubServer) {
+		s.refused["denied.buster.test"] = true
+		s.silent["slow.buster.test"] = true
+	})
+	d := newTestPlugin(t, s, &OptionsDNS{})
+	d.options.Timeout = 200 * time.Millisecond
+	for _, word := range []string{"denied", "slow"} {
+		results := make(chan lib.Result, 1)
+		if err := d.Run(context.Background(), word, results); err == nil {
+			t.Errorf("%s: got nil, want an error", word)
+		}
+		if len(results) != 0 {
+			t.Errorf("%s: got %+v, want no result", word, <-results)
+		}
+	}
+}
+
+func TestPreRunResolverError(t *testing.T) {
+	//解析器不可用时不能关闭泛解析检测
+	s := newStubServer(t, "buster.test", nil, nil)
+	d := newTestPlugin(t, s, &OptionsDNS{})
+	s.conn.Close()
+	if err := d.PreRun(context.Background()); err == nil {
+		t.Fatal("PreRun: got nil, want an error for an unreachable resolver")
+	}
+}
+
+func TestPreRunWildcard(t *testing.T) {
+	s := newStubServer(t, "buster.test", map[string]record{
+		"www.buster.test": {ip: "10.0.0.1"},
+	}, func(s *stubServer) { s.wildcard = "10.9.9.9" })
+
+	d := newTestPlugin(t, s, &OptionsDNS{})
+	err := d.PreRun(context.Background())
+	if _, ok := err.(*ErrWildcard); !ok {
+		t.Fatalf("PreRun: got %v, want ErrWildcard", err)
+	}
+
+	//强制继续时只报告与泛解析地址不同的结果
+	d = newTestPlugin(t, s, &OptionsDNS{WildcardForced: true})
+	if err := d.PreRun(context.Background()); err != nil {
+		t.Fatalf("PreRun with WildcardForced: %v", err)
+	}
+	if r := run(t, d, "www"); !r.Found {
+		t.Errorf("www: got %+v, want found", r)
+	}
+	results := make(chan lib.Result, 1)
+	if err := d.Run(context.Background(), "anything", results); err != nil {
+		t.Fatalf("Run(anything): %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("anything: got %+v, want the wildcard answer to be ignored", <-results)
+	}
+}
@@ -0,0 +1,28 @@
+package dns
+
+import "buster/lib"
+
+// PluginName 结果中标记的插件名称
+const PluginName = "dns"
+
+type Result struct {
+	Domain    string
+	Subdomain string
+	Word      string
+	Found     bool
+	IPs       []string
+	CNAME     string
+}
+
+// Fields 实现result接口
+func (r Result) Fields() lib.ResultFields {
+	return lib.ResultFields{
+		Plugin: PluginName,
+		Target: r.Domain,
+		Found:  r.Found,
+		Host:   r.Subdomain,
+		Word:   r.Word,
+		IPs:    r.IPs,
+		CNAME:  r.CNAME,
+	}
+}
@@ -0,0 +1,263 @@
+package fuzz
+
+import (
+	"bufio"
+	"buster/helper"
+	"buster/lib"
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// GobusterFuzz fuzz模式的核心实现,实现plugin接口,将url/header/cookie/body中的FUZZ替换为单词后发起请求
+type GobusterFuzz struct {
+	options     *OptionsFuzz
+	globalopts  *lib.Options
+	http        *lib.HTTPClient
+	matcher     *lib.ResponseMatcher
+	fuzzHeaders []lib.HTTPHeader //包含关键字的header,每次请求时替换
+	fuzzCookies bool
+}
+
+// NewGobusterFuzz 根据全局配置和fuzz配置生成GobusterFuzz(实现了plugin接口)
+func NewGobusterFuzz(globalopts *lib.Options, opts *OptionsFuzz) (*GobusterFuzz, error) {
+	if globalopts == nil {
+		return nil, fmt.Errorf("please provide valid global options")
+	}
+
+	if opts == nil {
+		return nil, fmt.Errorf("please provide valid plugin options")
+	}
+
+	g := GobusterFuzz{
+		options:    opts,
+		globalopts: globalopts,
+	}
+
+	//包含关键字的header和cookie不交给client,而是在每次请求时替换后单独设置
+	httpOpts := opts.HTTPOptions
+	httpOpts.Headers = nil
+	for _, h := range opts.Headers {
+		if strings.Contains(h.Name, lib.FuzzKeyword) || strings.Contains(h.Value, lib.FuzzKeyword) {
+			g.fuzzHeaders = append(g.fuzzHeaders, h)
+			continue
+		}
+		httpOpts.Headers = append(httpOpts.Headers, h)
+	}
+	if strings.Contains(opts.Cookies, lib.FuzzKeyword) {
+		g.fuzzCookies = true
+		httpOpts.Cookies = ""
+	}
+
+	hasFuzzTarget := strings.Contains(opts.URL, lib.FuzzKeyword) ||
+		strings.Contains(opts.RequestBody, lib.FuzzKeyword) ||
+		len(g.fuzzHeaders) > 0 || g.fuzzCookies
+	if !hasFuzzTarget {
+		return nil, fmt.Errorf("please provide the %s keyword in the url, headers, cookies or body", lib.FuzzKeyword)
+	}
+
+	m, err := lib.NewResponseMatcher(opts.Matchers)
+	if err != nil {
+		return nil, err
+	}
+	g.matcher = m
+
+	h, err := lib.NewHTTPClient(&httpOpts)
+	if err != nil {
+		return nil, err
+	}
+	g.http = h
+	return &g, nil
+}
+
+func (f *GobusterFuzz) Name() string {
+	return "fuzzing"
+}
+
+// RequestPerRun 每个单词只发起一次请求
+func (f *GobusterFuzz) RequestPerRun() int {
+	return 1
+}
+
+// PreRun 关键字的位置不确定,无法提前检查连接
+func (f *GobusterFuzz) PreRun(ctx context.Context) error {
+	return nil
+}
+
+// fuzzURL 将url中的关键字替换为word:?之前按照路径转义,之后按照查询参数转义,raw为true时原样替换
+func fuzzURL(rawURL, word string, raw bool) string {
+	if raw {
+		return strings.ReplaceAll(rawURL, lib.FuzzKeyword, word)
+	}
+	path, query := rawURL, ""
+	if i := strings.Index(rawURL, "?"); i >= 0 {
+		path, query = rawURL[:i], rawURL[i:]
+	}
+	return strings.ReplaceAll(path, lib.FuzzKeyword, url.PathEscape(word)) +
+		strings.ReplaceAll(query, lib.FuzzKeyword, url.QueryEscape(word))
+}
+
+// Run 将所有位置上的关键字替换为word后发起请求
+func (f *GobusterFuzz) Run(ctx context.Context, word string, results chan<- lib.Result) error {
+	url := fuzzURL(f.options.URL, word, f.options.RawURL)
+
+	requestOptions := lib.RequestOptions{}
+	for _, h := range f.fuzzHeaders {
+		requestOptions.ModifiedHeaders = append(requestOptions.ModifiedHeaders, lib.HTTPHeader{
+			Name:  strings.ReplaceAll(h.Name, lib.FuzzKeyword, word),
+			Value: strings.ReplaceAll(h.Value, lib.FuzzKeyword, word),
+		})
+	}
+	if f.fuzzCookies {
+		requestOptions.ModifiedHeaders = append(requestOptions.ModifiedHeaders, lib.HTTPHeader{
+			Name:  "Cookie",
+			Value: strings.ReplaceAll(f.options.Cookies, lib.FuzzKeyword, word),
+		})
+	}
+	if f.options.RequestBody != "" {
+		requestOptions.Body = strings.NewReader(strings.ReplaceAll(f.options.RequestBody, lib.FuzzKeyword, word))
+	}
+
+	requestOptions.ReturnBody = f.matcher.NeedsBody()
+	start := time.Now()
+	statusCode, size, header, body, err := f.http.Request(ctx, url, requestOptions)
+	duration := time.Since(start)
+	if err != nil {
+		return err
+	}
+	if statusCode == nil {
+		return nil
+	}
+
+	found := !f.options.ExcludedStatusCodesParsed.Contains(*statusCode) &&
+		!helper.SliceContains(f.options.ExcludeLength, int(size)) &&
+		f.matcher.Allow(&lib.MatchResponse{StatusCode: *statusCode, Size: size, Header: header, Body: body})
+	if found || f.globalopts.Verbose {
+		results <- Result{
+			Target:     f.options.URL,
+			Found:      found,
+			Word:       word,
+			URL:        url,
+			StatusCode: *statusCode,
+			Size:       size,
+			Header:     header,
+			Duration:   duration,
+		}
+	}
+	return nil
+}
+
+func (f *GobusterFuzz) GetConfigString() (string, error) {
+	var buf bytes.Buffer
+	bw := bufio.NewWriter(&buf)
+	tw := tabwriter.NewWriter(bw, 0, 5, 3, ' ', 0)
+	o := f.options
+
+	if _, err := fmt.Fprintf(tw, "[+] Url:\t%s\n", o.URL); err != nil {
+		return "", err
+	}
+
+	if _, err := fmt.Fprintf(tw, "[+] Method:\t%s\n", o.Method); err != nil {
+		return "", err
+	}
+
+	if _, err := fmt.Fprintf(tw, "[+] Threads:\t%d\n", f.globalopts.Threads); err != nil {
+		return "", err
+	}
+
+	if f.globalopts.Delay > 0 {
+		if _, err := fmt.Fprintf(tw, "[+] Delay:\t%s\n", f.globalopts.Delay); err != nil {
+			return "", err
+		}
+	}
+
+	wordlist := "stdin (pipe)"
+	if f.globalopts.Wordlist != "-" {
+		wordlist = f.globalopts.Wordlist
+	}
+	if _, err := fmt.Fprintf(tw, "[+] Wordlist:\t%s\n", wordlist); err != nil {
+		return "", err
+	}
+
+	if f.globalopts.PatternFile != "" {
+		if _, err := fmt.Fprintf(tw, "[+] Patterns:\t%s (%d entries)\n", f.globalopts.PatternFile, len(f.globalopts.Patterns)); err != nil {
+			return "", err
+		}
+	}
+
+	if o.ExcludedStatusCodesParsed.Length() > 0 {
+		if _, err := fmt.Fprintf(tw, "[+] Excluded Status codes:\t%s\n", o.ExcludedStatusCodesParsed.Stringify()); err != nil {
+			return "", err
+		}
+	}
+
+	if len(o.ExcludeLength) > 0 {
+		if _, err := fmt.Fprintf(tw, "[+] Exclude Length:\t%s\n", helper.JoinIntSlice(o.ExcludeLength)); err != nil {
+			return "", err
+		}
+	}
+
+	if o.RawURL {
+		if _, err := fmt.Fprintf(tw, "[+] Raw URL:\ttrue\n"); err != nil {
+			return "", err
+		}
+	}
+
+	if o.RequestBody != "" {
+		if _, err := fmt.Fprintf(tw, "[+] Body:\t%s\n", o.RequestBody); err != nil {
+			return "", err
+		}
+	}
+
+	if m := o.Matchers.Stringify(); m != "" {
+		if _, err := fmt.Fprintf(tw, "[+] Matchers:\t%s\n", m); err != nil {
+			return "", err
+		}
+	}
+
+	if err := o.WriteHTTPConfig(tw); err != nil {
+		return "", err
+	}
+
+	if o.Cookies != "" {
+		if _, err := fmt.Fprintf(tw, "[+] Cookies:\t%s\n", o.Cookies); err != nil {
+			return "", err
+		}
+	}
+
+	if o.Username != "" {
+		if _, err := fmt.Fprintf(tw, "[+] Auth User:\t%s\n", o.Username); err != nil {
+			return "", err
+		}
+	}
+
+	if o.FollowRedirect {
+		if _, err := fmt.Fprintf(tw, "[+] Follow Redirect:\ttrue\n"); err != nil {
+			return "", err
+		}
+	}
+
+	if f.globalopts.Verbose {
+		if _, err := fmt.Fprintf(tw, "[+] Verbose:\ttrue\n"); err != nil {
+			return "", err
+		}
+	}
+
+	if _, err := fmt.Fprintf(tw, "[+] Timeout:\t%s\n", o.Timeout.String()); err != nil {
+		return "", err
+	}
+
+	if err := tw.Flush(); err != nil {
+		return "", fmt.Errorf("error on tostring: %w", err)
+	}
+
+	if err := bw.Flush(); err != nil {
+		return "", fmt.Errorf("error on tostring: %w", err)
+	}
+
+	return strings.TrimSpace(buf.String()), nil
+}
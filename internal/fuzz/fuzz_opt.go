@@ -0,0 +1,20 @@
+package fuzz
+
+import "buster/lib"
+
+// OptionsFuzz fuzz模式的配置
+type OptionsFuzz struct {
+	lib.HTTPOptions
+	ExcludedStatusCodes       string
+	ExcludedStatusCodesParsed lib.IntSet
+	ExcludeLength             []int
+	Matchers                  lib.MatcherOptions
+	RequestBody               string
+	RawURL                    bool //url中的关键字原样替换为单词,不进行转义
+}
+
+func NewOptionsFuzz() *OptionsFuzz {
+	return &OptionsFuzz{
+		ExcludedStatusCodesParsed: lib.NewIntSet(),
+	}
+}
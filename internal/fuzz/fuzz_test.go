@@ -0,0 +1,63 @@
+package fuzz
+
+import (
+	"buster/lib"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// request 服务器收到的路径以及查询参数q
+type request struct {
+	path, query string
+}
+
+func newTestPlugin(t *testing.T, url string, raw bool) *GobusterFuzz {
+	t.Helper()
+	opts := NewOptionsFuzz()
+	opts.URL = url
+	opts.Timeout = time.Second
+	opts.RawURL = raw
+	f, err := NewGobusterFuzz(lib.NewOptions(), opts)
+	if err != nil {
+		t.Fatalf("NewGobusterFuzz: %v", err)
+	}
+	return f
+}
+
+func TestRunEscapesWords(t *testing.T) {
+	received := make(chan request, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- request{path: r.URL.Path, query: r.URL.Query().Get("q")}
+	}))
+	defer ts.Close()
+
+	tests := []struct {
+		word string
+		raw  bool
+		want request
+	}{
+		{"a b", false, request{"/a b", "a b"}},
+		{"a#b", false, request{"/a#b", "a#b"}},
+		{"100%", false, request{"/100%", "100%"}},
+		{"x?y=1&q=2", false, request{"/x?y=1&q=2", "x?y=1&q=2"}},
+		{"a/b", false, request{"/a/b", "a/b"}},
+		//原样替换时#之后的部分不会发送
+		{"a#b", true, request{"/a", ""}},
+		//已经编码的单词原样发送
+		{"a%20b", true, request{"/a b", "a b"}},
+	}
+	for _, tt := range tests {
+		f := newTestPlugin(t, ts.URL+"/"+lib.FuzzKeyword+"?q="+lib.FuzzKeyword, tt.raw)
+		results := make(chan lib.Result, 1)
+		if err := f.Run(context.Background(), tt.word, results); err != nil {
+			t.Errorf("%q (raw %v): %v", tt.word, tt.raw, err)
+			continue
+		}
+		if got := <-received; got != tt.want {
+			t.Errorf("%q (raw %v): got %+v, want %+v", tt.word, tt.raw, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package fuzz
+
+import (
+	"buster/lib"
+	"net/http"
+	"time"
+)
+
+// PluginName 结果中标记的插件名称
+const PluginName = "fuzz"
+
+type Result struct {
+	Target     string
+	Found      bool
+	Word       string
+	URL        string
+	StatusCode int
+	Size       int64
+	Header     http.Header
+	Duration   time.Duration
+}
+
+// Fields 实现result接口
+func (r Result) Fields() lib.ResultFields {
+	return lib.ResultFields{
+		Plugin:     PluginName,
+		Target:     r.Target,
+		Found:      r.Found,
+		URL:        r.URL,
+		Word:       r.Word,
+		StatusCode: r.StatusCode,
+		Size:       r.Size,
+		Header:     r.Header,
+		Duration:   r.Duration,
+	}
+}
@@ -0,0 +1,285 @@
+package gcs
+
+import (
+	"bufio"
+	"buster/lib"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// DefaultURL Google Cloud Storage的地址
+const DefaultURL = "https://storage.googleapis.com"
+
+// pageSize 统计文件数量时一次请求最多返回的文件数(JSON API的上限)
+const pageSize = 1000
+
+// bucketName 存储桶名称中允许的字符:小写字母、数字、点、横线和下划线,以字母或数字开头和结尾
+var bucketName = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*[a-z0-9]$`)
+
+// listObjects JSON API列出文件时返回的数据
+type listObjects struct {
+	Items []struct {
+		Name string `json:"name"`
+	} `json:"items"`
+	NextPageToken string `json:"nextPageToken"`
+}
+
+// apiError JSON API请求失败时返回的数据
+type apiError struct {
+	Error struct {
+		Errors []struct {
+			Reason string `json:"reason"`
+		} `json:"errors"`
+	} `json:"error"`
+}
+
+// GobusterGCS gcs模式的核心实现,实现plugin接口,将每个单词作为存储桶的名称进行访问
+type GobusterGCS struct {
+	options    *OptionsGCS
+	globalopts *lib.Options
+	http       *lib.HTTPClient
+}
+
+// NewGobusterGCS 根据全局配置和gcs配置生成GobusterGCS(实现了plugin接口)
+func NewGobusterGCS(globalopts *lib.Options, opts *OptionsGCS) (*GobusterGCS, error) {
+	if globalopts == nil {
+		return nil, fmt.Errorf("please provide valid global options")
+	}
+
+	if opts == nil {
+		return nil, fmt.Errorf("please provide valid plugin options")
+	}
+
+	if opts.URL == "" {
+		opts.URL = DefaultURL
+	}
+	if _, err := url.Parse(opts.URL); err != nil {
+		return nil, fmt.Errorf("invalid url %s: %w", opts.URL, err)
+	}
+	opts.URL = strings.TrimSuffix(opts.URL, "/")
+
+	g := GobusterGCS{
+		options:    opts,
+		globalopts: globalopts,
+	}
+
+	h, err := lib.NewHTTPClient(&opts.HTTPOptions)
+	if err != nil {
+		return nil, err
+	}
+	g.http = h
+	return &g, nil
+}
+
+func (g *GobusterGCS) Name() string {
+	return "GCS bucket enumeration"
+}
+
+// RequestPerRun 每个单词只发起一次请求
+func (g *GobusterGCS) RequestPerRun() int {
+	return 1
+}
+
+// PreRun 请求一个随机名称的存储桶,确认地址可以访问并且不会对任意名称都返回存在
+func (g *GobusterGCS) PreRun(ctx context.Context) error {
+	bucket := uuid.New().String()
+	status, _, _, _, err := g.http.Request(ctx, g.listURL(bucket), lib.RequestOptions{Method: http.MethodGet})
+	if err != nil {
+		return fmt.Errorf("unable to connect to %s: %w", g.options.URL, err)
+	}
+	if status == nil {
+		return ctx.Err()
+	}
+	if *status != http.StatusNotFound {
+		return fmt.Errorf("the url %s returned status %d for the non existing bucket %s, expected 404", g.options.URL, *status, bucket)
+	}
+	return nil
+}
+
+// Run 通过JSON API列出以word为名称的存储桶中的文件,根据状态码以及错误原因判断是否存在以及是否公开
+func (g *GobusterGCS) Run(ctx context.Context, word string, results chan<- lib.Result) error {
+	bucket := strings.ToLower(word)
+	//不符合规则以及保留的名称不可能存在,直接跳过
+	if !validBucketName(bucket) || strings.HasPrefix(bucket, "goog") || strings.Contains(bucket, "google") {
+		return nil
+	}
+
+	start := time.Now()
+	//JSON API只接受GET,不使用全局配置的method
+	status, size, header, body, err := g.http.Request(ctx, g.listURL(bucket), lib.RequestOptions{Method: http.MethodGet, ReturnBody: true})
+	duration := time.Since(start)
+	if err != nil {
+		return err
+	}
+	if status == nil {
+		return nil
+	}
+
+	result := Result{
+		BaseURL:    g.options.URL,
+		Bucket:     bucket,
+		URL:        fmt.Sprintf("%s/%s", g.options.URL, bucket),
+		Word:       word,
+		StatusCode: *status,
+		Size:       size,
+		Header:     header,
+		Duration:   duration,
+	}
+	switch *status {
+	case http.StatusOK:
+		var list listObjects
+		if err := json.Unmarshal(body, &list); err != nil {
+			return fmt.Errorf("could not parse object listing of %s: %w", result.URL, err)
+		}
+		result.Found = true
+		result.Access = AccessPublic
+		//只请求一页,还有下一页时数量只是下限
+		result.Objects = strconv.Itoa(len(list.Items))
+		if list.NextPageToken != "" {
+			result.Objects += "+"
+		}
+		for i := 0; i < len(list.Items) && i < g.options.MaxFiles; i++ {
+			result.Names = append(result.Names, list.Items[i].Name)
+		}
+	default:
+		result.Found, result.Access = classifyError(*status, body)
+	}
+
+	if result.Found || g.globalopts.Verbose {
+		results <- result
+	}
+	return nil
+}
+
+// classifyError 根据状态码以及错误响应中的reason判断存储桶是否存在以及无法列出文件的原因
+func classifyError(status int, body []byte) (bool, string) {
+	reasons := make(map[string]bool)
+	var e apiError
+	if json.Unmarshal(body, &e) == nil {
+		for _, r := range e.Error.Errors {
+			reasons[r.Reason] = true
+		}
+	}
+	switch {
+	case reasons["notFound"]:
+		return false, ""
+	case reasons["accountDisabled"] || reasons["userProjectAccountProblem"]:
+		return true, AccessBillingDisabled
+	case status == http.StatusBadRequest && (reasons["required"] || reasons["userProjectMissing"]):
+		return true, AccessRequesterPays
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return true, AccessPrivate
+	}
+	return false, ""
+}
+
+// validBucketName 名称为3-63位,包含点时最长222位,每个以点分隔的部分不超过63位
+func validBucketName(bucket string) bool {
+	if !bucketName.MatchString(bucket) || len(bucket) < 3 {
+		return false
+	}
+	if !strings.Contains(bucket, ".") {
+		return len(bucket) <= 63
+	}
+	if len(bucket) > 222 {
+		return false
+	}
+	for _, part := range strings.Split(bucket, ".") {
+		if part == "" || len(part) > 63 {
+			return false
+		}
+	}
+	return true
+}
+
+// listURL 通过JSON API列出存储桶中文件的请求地址
+func (g *GobusterGCS) listURL(bucket string) string {
+	return fmt.Sprintf("%s/storage/v1/b/%s/o?maxResults=%d&fields=items(name),nextPageToken", g.options.URL, bucket, pageSize)
+}
+
+func (g *GobusterGCS) GetConfigString() (string, error) {
+	var buf bytes.Buffer
+	bw := bufio.NewWriter(&buf)
+	tw := tabwriter.NewWriter(bw, 0, 5, 3, ' ', 0)
+	o := g.options
+
+	if _, err := fmt.Fprintf(tw, "[+] Url:\t%s\n", o.URL); err != nil {
+		return "", err
+	}
+
+	if _, err := fmt.Fprintf(tw, "[+] Threads:\t%d\n", g.globalopts.Threads); err != nil {
+		return "", err
+	}
+
+	if g.globalopts.Delay > 0 {
+		if _, err := fmt.Fprintf(tw, "[+] Delay:\t%s\n", g.globalopts.Delay); err != nil {
+			return "", err
+		}
+	}
+
+	wordlist := "stdin (pipe)"
+	if g.globalopts.Wordlist != "-" {
+		wordlist = g.globalopts.Wordlist
+	}
+	if _, err := fmt.Fprintf(tw, "[+] Wordlist:\t%s\n", wordlist); err != nil {
+		return "", err
+	}
+
+	if g.globalopts.PatternFile != "" {
+		if _, err := fmt.Fprintf(tw, "[+] Patterns:\t%s (%d entries)\n", g.globalopts.PatternFile, len(g.globalopts.Patterns)); err != nil {
+			return "", err
+		}
+	}
+
+	if o.MaxFiles > 0 {
+		if _, err := fmt.Fprintf(tw, "[+] Max files to list:\t%d\n", o.MaxFiles); err != nil {
+			return "", err
+		}
+	}
+
+	if err := o.WriteHTTPConfig(tw); err != nil {
+		return "", err
+	}
+
+	if o.Cookies != "" {
+		if _, err := fmt.Fprintf(tw, "[+] Cookies:\t%s\n", o.Cookies); err != nil {
+			return "", err
+		}
+	}
+
+	if o.Username != "" {
+		if _, err := fmt.Fprintf(tw, "[+] Auth User:\t%s\n", o.Username); err != nil {
+			return "", err
+		}
+	}
+
+	if g.globalopts.Verbose {
+		if _, err := fmt.Fprintf(tw, "[+] Verbose:\ttrue\n"); err != nil {
+			return "", err
+		}
+	}
+
+	if _, err := fmt.Fprintf(tw, "[+] Timeout:\t%s\n", o.Timeout.String()); err != nil {
+		return "", err
+	}
+
+	if err := tw.Flush(); err != nil {
+		return "", fmt.Errorf("error on tostring: %w", err)
+	}
+
+	if err := bw.Flush(); err != nil {
+		return "", fmt.Errorf("error on tostring: %w", err)
+	}
+
+	return strings.TrimSpace(buf.String()), nil
+}
@@ -0,0 +1,13 @@
+package gcs
+
+import "buster/lib"
+
+// OptionsGCS gcs模式的配置,URL为存储服务的地址
+type OptionsGCS struct {
+	lib.HTTPOptions
+	MaxFiles int //存储桶可以列出时最多显示的文件数,0表示不列出
+}
+
+func NewOptionsGCS() *OptionsGCS {
+	return &OptionsGCS{}
+}
@@ -0,0 +1,172 @@
+package gcs
+
+import (
+	"buster/lib"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestServer 模拟JSON API:files公开,big公开并且有下一页,secret需要认证,other拒绝访问,
+// billing的结算账号被停用,payer需要请求者付费,hidden返回403但原因是不存在,其他存储桶不存在;只接受GET
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		bucket := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/storage/v1/b/"), "/o")
+		switch bucket {
+		case "files":
+			fmt.Fprint(w, `{"items":[{"name":"a.txt"},{"name":"b/c.png"},{"name":"d.zip"}]}`)
+		case "big":
+			fmt.Fprint(w, `{"items":[{"name":"f1"}],"nextPageToken":"next"}`)
+		case "secret":
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":{"code":401}}`)
+		case "other":
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"error":{"code":403,"errors":[{"reason":"forbidden"}]}}`)
+		case "billing":
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"error":{"code":403,"errors":[{"reason":"accountDisabled"}]}}`)
+		case "payer":
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":{"code":400,"errors":[{"reason":"required"}]}}`)
+		case "hidden":
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"error":{"code":403,"errors":[{"reason":"notFound"}]}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error":{"code":404}}`)
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func newTestPlugin(t *testing.T, baseURL string) *GobusterGCS {
+	t.Helper()
+	globalopts := lib.NewOptions()
+	globalopts.Verbose = true
+	opts := NewOptionsGCS()
+	opts.URL = baseURL
+	opts.MaxFiles = 2
+	opts.Timeout = time.Second
+	//全局的method不影响JSON API的请求
+	opts.Method = http.MethodPost
+	g, err := NewGobusterGCS(globalopts, opts)
+	if err != nil {
+		t.Fatalf("NewGobusterGCS: %v", err)
+	}
+	return g
+}
+
+func run(t *testing.T, g *GobusterGCS, word string) Result {
+	t.Helper()
+	results := make(chan lib.Result, 1)
+	if err := g.Run(context.Background(), word, results); err != nil {
+		t.Fatalf("Run(%s): %v", word, err)
+	}
+	select {
+	case r := <-results:
+		return r.(Result)
+	default:
+		t.Fatalf("Run(%s) returned no result", word)
+		return Result{}
+	}
+}
+
+func TestRun(t *testing.T) {
+	ts := newTestServer(t)
+	g := newTestPlugin(t, ts.URL)
+	if err := g.PreRun(context.Background()); err != nil {
+		t.Fatalf("PreRun: %v", err)
+	}
+
+	r := run(t, g, "files")
+	if !r.Found || r.Access != AccessPublic || r.Objects != "3" {
+		t.Fatalf("files: got %+v, want a public bucket with 3 objects", r)
+	}
+	if len(r.Names) != 2 || r.Names[0] != "a.txt" || r.Names[1] != "b/c.png" {
+		t.Errorf("files: got names %v, want the first 2 names", r.Names)
+	}
+
+	r = run(t, g, "big")
+	if !r.Found || r.Objects != "1+" {
+		t.Errorf("big: got objects %q, want 1+", r.Objects)
+	}
+
+	tests := []struct {
+		word   string
+		found  bool
+		access string
+	}{
+		{"secret", true, AccessPrivate},
+		{"other", true, AccessPrivate},
+		{"billing", true, AccessBillingDisabled},
+		{"payer", true, AccessRequesterPays},
+		{"hidden", false, ""},
+		{"missing", false, ""},
+	}
+	for _, tt := range tests {
+		r := run(t, g, tt.word)
+		if r.Found != tt.found || r.Access != tt.access {
+			t.Errorf("%s: got found=%v access=%q, want found=%v access=%q", tt.word, r.Found, r.Access, tt.found, tt.access)
+		}
+	}
+}
+
+func TestRunInvalidName(t *testing.T) {
+	ts := newTestServer(t)
+	g := newTestPlugin(t, ts.URL)
+	//不符合规则以及保留的名称不发送请求
+	long := strings.Repeat("a", 63)
+	for _, word := range []string{"ab", "-files", "google-files", "goog1", strings.Repeat("a", 64),
+		"a..b", long + "." + strings.Repeat("a", 64), strings.Repeat(long+".", 3) + long} {
+		results := make(chan lib.Result, 1)
+		if err := g.Run(context.Background(), word, results); err != nil {
+			t.Fatalf("Run(%s): %v", word, err)
+		}
+		if len(results) != 0 {
+			t.Errorf("%s: got %+v, want no result", word, <-results)
+		}
+	}
+}
+
+func TestRunDottedName(t *testing.T) {
+	//包含点的名称最长222位
+	name := strings.Repeat(strings.Repeat("a", 63)+".", 3) + strings.Repeat("b", 30)
+	var requested string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/storage/v1/b/"), "/o")
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer ts.Close()
+	g := newTestPlugin(t, ts.URL)
+	if r := run(t, g, name); !r.Found || requested != name {
+		t.Errorf("got found=%v for %d characters (requested %q), want found", r.Found, len(name), requested)
+	}
+}
+
+func TestPreRun(t *testing.T) {
+	//对任意存储桶都要求认证的地址
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer ts.Close()
+	g := newTestPlugin(t, ts.URL)
+	if err := g.PreRun(context.Background()); err == nil {
+		t.Fatal("PreRun: got nil, want an error for a url that reports every bucket")
+	}
+
+	ts.Close()
+	if err := g.PreRun(context.Background()); err == nil {
+		t.Fatal("PreRun: got nil, want an error for an unreachable url")
+	}
+}
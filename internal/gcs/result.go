@@ -0,0 +1,55 @@
+package gcs
+
+import (
+	"buster/lib"
+	"net/http"
+	"time"
+)
+
+// PluginName 结果中标记的插件名称
+const PluginName = "gcs"
+
+// 存储桶的访问权限
+const (
+	AccessPublic          = "public"           //可以匿名列出文件
+	AccessPrivate         = "private"          //存在但是需要认证或拒绝访问
+	AccessBillingDisabled = "billing disabled" //存在但是所属项目的结算账号被停用
+	AccessRequesterPays   = "requester pays"   //存在但是需要由请求者付费
+)
+
+type Result struct {
+	BaseURL    string
+	Found      bool
+	Bucket     string
+	URL        string
+	Word       string
+	Access     string
+	Objects    string   //可以列出的文件数量,超过一页时以+结尾
+	Names      []string //列出的文件
+	StatusCode int
+	Size       int64
+	Header     http.Header
+	Duration   time.Duration
+}
+
+// Fields 实现result接口
+func (r Result) Fields() lib.ResultFields {
+	info := r.Access
+	if r.Objects != "" {
+		info = info + ", " + r.Objects + " objects"
+	}
+	return lib.ResultFields{
+		Plugin:     PluginName,
+		Target:     r.BaseURL,
+		Found:      r.Found,
+		URL:        r.URL,
+		Host:       r.Bucket,
+		Word:       r.Word,
+		StatusCode: r.StatusCode,
+		Size:       r.Size,
+		Header:     r.Header,
+		Duration:   r.Duration,
+		Info:       info,
+		Items:      r.Names,
+	}
+}
@@ -0,0 +1,342 @@
+package params
+
+import (
+	"bufio"
+	"buster/lib"
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// response 一次请求的响应
+type response struct {
+	url        string
+	statusCode int
+	size       int64
+	header     http.Header
+	body       []byte
+	duration   time.Duration
+}
+
+// GobusterParams params模式的核心实现,实现plugin接口,将多个单词作为参数放在同一个请求中,
+// 响应与基准不同时二分查找引起变化的参数
+type GobusterParams struct {
+	options    *OptionsParams
+	globalopts *lib.Options
+	http       *lib.HTTPClient
+	inBody     bool   //参数放在请求体中,否则放在url中
+	canary     string //参数值的前缀,用于判断是否被反射
+	baseline   *response
+	stableSize bool //基准响应的大小是否固定,不固定时不比较大小
+	reflectAll bool //页面会原样输出任意参数,此时只比较状态码
+
+	mu      sync.Mutex
+	pending []string //还没有凑满一批的参数
+}
+
+// NewGobusterParams 根据全局配置和params配置生成GobusterParams(实现了plugin接口)
+func NewGobusterParams(globalopts *lib.Options, opts *OptionsParams) (*GobusterParams, error) {
+	if globalopts == nil {
+		return nil, fmt.Errorf("please provide valid global options")
+	}
+
+	if opts == nil {
+		return nil, fmt.Errorf("please provide valid plugin options")
+	}
+
+	if opts.BatchSize <= 0 {
+		return nil, fmt.Errorf("batch size must be bigger than 0")
+	}
+
+	g := GobusterParams{
+		options:    opts,
+		globalopts: globalopts,
+		canary:     "bp" + strings.ReplaceAll(uuid.New().String(), "-", "")[:6],
+	}
+
+	//GET和HEAD请求的参数放在url中,其余的放在请求体中
+	httpOpts := opts.HTTPOptions
+	g.inBody = opts.Method != "" && opts.Method != http.MethodGet && opts.Method != http.MethodHead
+	if g.inBody {
+		hasContentType := false
+		for _, h := range opts.Headers {
+			if strings.EqualFold(h.Name, "Content-Type") {
+				hasContentType = true
+				break
+			}
+		}
+		if !hasContentType {
+			httpOpts.Headers = append(httpOpts.Headers, lib.HTTPHeader{Name: "Content-Type", Value: "application/x-www-form-urlencoded"})
+		}
+	}
+
+	h, err := lib.NewHTTPClient(&httpOpts)
+	if err != nil {
+		return nil, err
+	}
+	g.http = h
+	return &g, nil
+}
+
+func (p *GobusterParams) Name() string {
+	return "parameter discovery"
+}
+
+// RequestPerRun 多个单词共用一个请求,响应变化时还会二分查找,请求数不固定,进度按照单词计算
+func (p *GobusterParams) RequestPerRun() int {
+	return 1
+}
+
+// ProgressUnit 进度显示为已处理的单词数,而不是请求数
+func (p *GobusterParams) ProgressUnit() string {
+	return "words"
+}
+
+// PreRun 使用一个不存在的参数请求两次作为基准,判断响应的大小是否固定以及参数是否会被原样输出
+func (p *GobusterParams) PreRun(ctx context.Context) error {
+	name := uuid.New().String()
+	var responses []*response
+	for i := 0; i < 2; i++ {
+		resp, values, err := p.send(ctx, []string{name})
+		if err != nil {
+			return fmt.Errorf("unable to connect to %s: %w", p.options.URL, err)
+		}
+		if resp == nil {
+			return ctx.Err()
+		}
+		if bytes.Contains(resp.body, []byte(values[0])) {
+			p.reflectAll = true
+		}
+		responses = append(responses, resp)
+	}
+	if responses[0].statusCode != responses[1].statusCode {
+		return fmt.Errorf("the status code of %s is not stable (%d and %d)", p.options.URL, responses[0].statusCode, responses[1].statusCode)
+	}
+	p.baseline = responses[0]
+	p.stableSize = !p.reflectAll && responses[0].size == responses[1].size
+	return nil
+}
+
+// Run 缓存单词,凑满一批后发起请求
+func (p *GobusterParams) Run(ctx context.Context, word string, results chan<- lib.Result) error {
+	p.mu.Lock()
+	p.pending = append(p.pending, word)
+	if len(p.pending) < p.options.BatchSize {
+		p.mu.Unlock()
+		return nil
+	}
+	batch := p.pending
+	p.pending = nil
+	p.mu.Unlock()
+
+	return p.testBatch(ctx, batch, results)
+}
+
+// PostRun 处理最后不足一批的单词
+func (p *GobusterParams) PostRun(ctx context.Context, results chan<- lib.Result) error {
+	p.mu.Lock()
+	batch := p.pending
+	p.pending = nil
+	p.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+	return p.testBatch(ctx, batch, results)
+}
+
+// testBatch 测试一批参数,失败时返回整批的单词,由引擎全部记录为失败
+func (p *GobusterParams) testBatch(ctx context.Context, batch []string, results chan<- lib.Result) error {
+	if err := p.test(ctx, batch, results); err != nil {
+		return &lib.ErrWords{Words: batch, Err: err}
+	}
+	return nil
+}
+
+// test 使用一批参数发起请求,响应与基准不同时分为两半分别测试,直到找到单个的参数
+func (p *GobusterParams) test(ctx context.Context, params []string, results chan<- lib.Result) error {
+	resp, values, err := p.send(ctx, params)
+	if err != nil {
+		return err
+	}
+	if resp == nil {
+		return nil
+	}
+
+	changes := p.compare(resp, values)
+	if len(changes) == 0 {
+		return nil
+	}
+	if len(params) == 1 {
+		results <- Result{
+			Target:     p.options.URL,
+			URL:        resp.url,
+			Param:      params[0],
+			StatusCode: resp.statusCode,
+			Size:       resp.size,
+			Header:     resp.header,
+			Duration:   resp.duration,
+			Changes:    changes,
+		}
+		return nil
+	}
+
+	mid := len(params) / 2
+	if err := p.test(ctx, params[:mid], results); err != nil {
+		return err
+	}
+	return p.test(ctx, params[mid:], results)
+}
+
+// compare 返回响应与基准的区别
+func (p *GobusterParams) compare(resp *response, values []string) []string {
+	var changes []string
+	if resp.statusCode != p.baseline.statusCode {
+		changes = append(changes, fmt.Sprintf("status %d -> %d", p.baseline.statusCode, resp.statusCode))
+	}
+	if p.stableSize && resp.size != p.baseline.size {
+		changes = append(changes, fmt.Sprintf("size %d -> %d", p.baseline.size, resp.size))
+	}
+	if !p.reflectAll {
+		for _, v := range values {
+			if bytes.Contains(resp.body, []byte(v)) {
+				changes = append(changes, "reflected")
+				break
+			}
+		}
+	}
+	return changes
+}
+
+// send 将参数放在url或者请求体中发起请求,返回响应以及每个参数使用的值
+func (p *GobusterParams) send(ctx context.Context, params []string) (*response, []string, error) {
+	values := make([]string, len(params))
+	pairs := make([]string, len(params))
+	for i, name := range params {
+		//值以z结尾,避免一个值是另一个值的前缀
+		values[i] = fmt.Sprintf("%s%dz", p.canary, i)
+		pairs[i] = url.QueryEscape(name) + "=" + values[i]
+	}
+	query := strings.Join(pairs, "&")
+
+	reqURL := p.options.URL
+	opts := lib.RequestOptions{ReturnBody: true}
+	if p.inBody {
+		body := query
+		if p.options.RequestBody != "" {
+			body = p.options.RequestBody + "&" + query
+		}
+		opts.Body = strings.NewReader(body)
+	} else if strings.Contains(reqURL, "?") {
+		reqURL = reqURL + "&" + query
+	} else {
+		reqURL = reqURL + "?" + query
+	}
+
+	start := time.Now()
+	status, size, header, body, err := p.http.Request(ctx, reqURL, opts)
+	duration := time.Since(start)
+	if err != nil {
+		return nil, nil, err
+	}
+	if status == nil {
+		return nil, nil, nil
+	}
+	return &response{url: reqURL, statusCode: *status, size: size, header: header, body: body, duration: duration}, values, nil
+}
+
+func (p *GobusterParams) GetConfigString() (string, error) {
+	var buf bytes.Buffer
+	bw := bufio.NewWriter(&buf)
+	tw := tabwriter.NewWriter(bw, 0, 5, 3, ' ', 0)
+	o := p.options
+
+	if _, err := fmt.Fprintf(tw, "[+] Url:\t%s\n", o.URL); err != nil {
+		return "", err
+	}
+
+	if _, err := fmt.Fprintf(tw, "[+] Method:\t%s\n", o.Method); err != nil {
+		return "", err
+	}
+
+	if _, err := fmt.Fprintf(tw, "[+] Threads:\t%d\n", p.globalopts.Threads); err != nil {
+		return "", err
+	}
+
+	if p.globalopts.Delay > 0 {
+		if _, err := fmt.Fprintf(tw, "[+] Delay:\t%s\n", p.globalopts.Delay); err != nil {
+			return "", err
+		}
+	}
+
+	wordlist := "stdin (pipe)"
+	if p.globalopts.Wordlist != "-" {
+		wordlist = p.globalopts.Wordlist
+	}
+	if _, err := fmt.Fprintf(tw, "[+] Wordlist:\t%s\n", wordlist); err != nil {
+		return "", err
+	}
+
+	if p.globalopts.PatternFile != "" {
+		if _, err := fmt.Fprintf(tw, "[+] Patterns:\t%s (%d entries)\n", p.globalopts.PatternFile, len(p.globalopts.Patterns)); err != nil {
+			return "", err
+		}
+	}
+
+	location := "query"
+	if p.inBody {
+		location = "body"
+	}
+	if _, err := fmt.Fprintf(tw, "[+] Parameters:\t%d per request in %s\n", o.BatchSize, location); err != nil {
+		return "", err
+	}
+
+	if err := o.WriteHTTPConfig(tw); err != nil {
+		return "", err
+	}
+
+	if o.Cookies != "" {
+		if _, err := fmt.Fprintf(tw, "[+] Cookies:\t%s\n", o.Cookies); err != nil {
+			return "", err
+		}
+	}
+
+	if o.Username != "" {
+		if _, err := fmt.Fprintf(tw, "[+] Auth User:\t%s\n", o.Username); err != nil {
+			return "", err
+		}
+	}
+
+	if o.FollowRedirect {
+		if _, err := fmt.Fprintf(tw, "[+] Follow Redirect:\ttrue\n"); err != nil {
+			return "", err
+		}
+	}
+
+	if p.globalopts.Verbose {
+		if _, err := fmt.Fprintf(tw, "[+] Verbose:\ttrue\n"); err != nil {
+			return "", err
+		}
+	}
+
+	if _, err := fmt.Fprintf(tw, "[+] Timeout:\t%s\n", o.Timeout.String()); err != nil {
+		return "", err
+	}
+
+	if err := tw.Flush(); err != nil {
+		return "", fmt.Errorf("error on tostring: %w", err)
+	}
+
+	if err := bw.Flush(); err != nil {
+		return "", fmt.Errorf("error on tostring: %w", err)
+	}
+
+	return strings.TrimSpace(buf.String()), nil
+}
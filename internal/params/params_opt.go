@@ -0,0 +1,14 @@
+package params
+
+import "buster/lib"
+
+// OptionsParams params模式的配置
+type OptionsParams struct {
+	lib.HTTPOptions
+	BatchSize   int    //每个请求中携带的参数数量
+	RequestBody string //请求体,参数放在请求体中时追加在其后
+}
+
+func NewOptionsParams() *OptionsParams {
+	return &OptionsParams{}
+}
@@ -0,0 +1,119 @@
+package params
+
+import (
+	"buster/lib"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// newTestServer 只有debug、id和admin三个参数有效:debug改变大小,id的值会被反射,admin返回403
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.Form.Get("admin") != "" {
+			w.WriteHeader(http.StatusForbidden)
+		}
+		fmt.Fprint(w, "<html>hello")
+		if r.Form.Get("debug") != "" {
+			fmt.Fprint(w, " debug mode enabled")
+		}
+		if id := r.Form.Get("id"); id != "" {
+			fmt.Fprintf(w, " item %s", id)
+		}
+		fmt.Fprint(w, "</html>")
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestRunBisect(t *testing.T) {
+	ts := newTestServer(t)
+	words := []string{"a", "debug", "b", "c", "id", "d", "e", "admin", "f"}
+
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		opts := NewOptionsParams()
+		opts.URL = ts.URL
+		opts.Method = method
+		opts.Timeout = time.Second
+		opts.BatchSize = 4
+		p, err := NewGobusterParams(lib.NewOptions(), opts)
+		if err != nil {
+			t.Fatalf("NewGobusterParams: %v", err)
+		}
+		if err := p.PreRun(context.Background()); err != nil {
+			t.Fatalf("%s PreRun: %v", method, err)
+		}
+
+		//每4个参数一批,最后一个参数由PostRun处理
+		results := make(chan lib.Result, len(words))
+		for _, word := range words {
+			if err := p.Run(context.Background(), word, results); err != nil {
+				t.Fatalf("%s Run(%s): %v", method, word, err)
+			}
+		}
+		if err := p.PostRun(context.Background(), results); err != nil {
+			t.Fatalf("%s PostRun: %v", method, err)
+		}
+		close(results)
+
+		found := make(map[string][]string)
+		var names []string
+		for r := range results {
+			res := r.(Result)
+			found[res.Param] = res.Changes
+			names = append(names, res.Param)
+		}
+		sort.Strings(names)
+		if want := []string{"admin", "debug", "id"}; !reflect.DeepEqual(names, want) {
+			t.Errorf("%s: got params %v, want %v", method, names, want)
+		}
+		if changes := found["id"]; len(changes) == 0 || changes[len(changes)-1] != "reflected" {
+			t.Errorf("%s: got changes %v for id, want reflected", method, changes)
+		}
+		if changes := found["admin"]; len(changes) == 0 || changes[0] != "status 200 -> 403" {
+			t.Errorf("%s: got changes %v for admin, want a status change", method, changes)
+		}
+	}
+}
+
+func TestRunBatchError(t *testing.T) {
+	//请求失败时整批的单词都需要记录为失败
+	ts := newTestServer(t)
+	opts := NewOptionsParams()
+	opts.URL = ts.URL
+	opts.Timeout = time.Second
+	opts.BatchSize = 3
+	p, err := NewGobusterParams(lib.NewOptions(), opts)
+	if err != nil {
+		t.Fatalf("NewGobusterParams: %v", err)
+	}
+	if err := p.PreRun(context.Background()); err != nil {
+		t.Fatalf("PreRun: %v", err)
+	}
+	ts.Close()
+
+	results := make(chan lib.Result, 1)
+	for _, word := range []string{"a", "b"} {
+		if err := p.Run(context.Background(), word, results); err != nil {
+			t.Fatalf("Run(%s): %v", word, err)
+		}
+	}
+	err = p.Run(context.Background(), "c", results)
+	words, ok := err.(*lib.ErrWords)
+	if !ok {
+		t.Fatalf("got %v, want ErrWords", err)
+	}
+	if !reflect.DeepEqual(words.Words, []string{"a", "b", "c"}) {
+		t.Errorf("got words %v, want the whole batch", words.Words)
+	}
+}
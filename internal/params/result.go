@@ -0,0 +1,38 @@
+package params
+
+import (
+	"buster/lib"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// PluginName 结果中标记的插件名称
+const PluginName = "params"
+
+type Result struct {
+	Target     string
+	URL        string
+	Param      string
+	StatusCode int
+	Size       int64
+	Header     http.Header
+	Duration   time.Duration
+	Changes    []string //与基准响应的区别,如状态码、大小以及参数值被反射
+}
+
+// Fields 实现result接口
+func (r Result) Fields() lib.ResultFields {
+	return lib.ResultFields{
+		Plugin:     PluginName,
+		Target:     r.Target,
+		Found:      true,
+		URL:        r.URL,
+		Word:       r.Param,
+		StatusCode: r.StatusCode,
+		Size:       r.Size,
+		Header:     r.Header,
+		Duration:   r.Duration,
+		Info:       strings.Join(r.Changes, ", "),
+	}
+}
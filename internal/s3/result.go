@@ -0,0 +1,49 @@
+package s3
+
+import (
+	"buster/lib"
+	"net/http"
+	"time"
+)
+
+// PluginName 结果中标记的插件名称
+const PluginName = "s3"
+
+// 存储桶的访问权限
+const (
+	AccessListable    = "listable"     //可以匿名列出文件
+	AccessDenied      = "denied"       //存在但是拒绝访问
+	AccessOtherRegion = "other region" //存在但是位于其他区域
+)
+
+type Result struct {
+	Endpoint   string
+	Found      bool
+	Bucket     string
+	URL        string
+	Word       string
+	Access     string
+	Keys       []string //列出的文件
+	StatusCode int
+	Size       int64
+	Header     http.Header
+	Duration   time.Duration
+}
+
+// Fields 实现result接口
+func (r Result) Fields() lib.ResultFields {
+	return lib.ResultFields{
+		Plugin:     PluginName,
+		Target:     r.Endpoint,
+		Found:      r.Found,
+		URL:        r.URL,
+		Host:       r.Bucket,
+		Word:       r.Word,
+		StatusCode: r.StatusCode,
+		Size:       r.Size,
+		Header:     r.Header,
+		Duration:   r.Duration,
+		Info:       r.Access,
+		Items:      r.Keys,
+	}
+}
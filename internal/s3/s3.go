@@ -0,0 +1,261 @@
+package s3
+
+import (
+	"bufio"
+	"buster/lib"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"github.com/google/uuid"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// DefaultEndpoint AWS S3的地址
+const DefaultEndpoint = "https://s3.amazonaws.com"
+
+// bucketName 存储桶名称的规则:3-63位的小写字母、数字、点和横线,以字母或数字开头和结尾
+var bucketName = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+
+// listBucketResult 列出存储桶时返回的xml
+type listBucketResult struct {
+	XMLName  xml.Name `xml:"ListBucketResult"`
+	Name     string   `xml:"Name"`
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+}
+
+// GobusterS3 s3模式的核心实现,实现plugin接口,将每个单词作为存储桶的名称进行访问
+type GobusterS3 struct {
+	options    *OptionsS3
+	globalopts *lib.Options
+	http       *lib.HTTPClient
+	endpoint   *url.URL
+}
+
+// NewGobusterS3 根据全局配置和s3配置生成GobusterS3(实现了plugin接口)
+func NewGobusterS3(globalopts *lib.Options, opts *OptionsS3) (*GobusterS3, error) {
+	if globalopts == nil {
+		return nil, fmt.Errorf("please provide valid global options")
+	}
+
+	if opts == nil {
+		return nil, fmt.Errorf("please provide valid plugin options")
+	}
+
+	if opts.Endpoint == "" {
+		opts.Endpoint = DefaultEndpoint
+	}
+	u, err := url.Parse(opts.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint %s: %w", opts.Endpoint, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("invalid endpoint %s: must be a http or https url", opts.Endpoint)
+	}
+
+	g := GobusterS3{
+		options:    opts,
+		globalopts: globalopts,
+		endpoint:   u,
+	}
+
+	//匿名访问,不跟随重定向(其他区域的存储桶返回301)
+	h, err := lib.NewHTTPClient(&lib.HTTPOptions{BasicHTTPOptions: opts.BasicHTTPOptions})
+	if err != nil {
+		return nil, err
+	}
+	g.http = h
+	return &g, nil
+}
+
+func (s *GobusterS3) Name() string {
+	return "S3 bucket enumeration"
+}
+
+// RequestPerRun 每个单词只发起一次请求
+func (s *GobusterS3) RequestPerRun() int {
+	return 1
+}
+
+// PreRun 请求一个随机名称的存储桶,确认地址可以访问并且不会对任意名称都返回存在
+func (s *GobusterS3) PreRun(ctx context.Context) error {
+	bucket := uuid.New().String()
+	status, _, _, _, err := s.http.Request(ctx, s.listURL(s.bucketURL(bucket)), lib.RequestOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to connect to %s: %w", s.options.Endpoint, err)
+	}
+	if status == nil {
+		return ctx.Err()
+	}
+	if *status != http.StatusNotFound {
+		return fmt.Errorf("the endpoint %s returned status %d for the non existing bucket %s, expected 404", s.options.Endpoint, *status, bucket)
+	}
+	return nil
+}
+
+// Run 请求以word为名称的存储桶,根据状态码判断是否存在以及是否可以列出
+func (s *GobusterS3) Run(ctx context.Context, word string, results chan<- lib.Result) error {
+	bucket := strings.ToLower(word)
+	//不符合规则的名称不可能存在,直接跳过
+	if !bucketName.MatchString(bucket) {
+		return nil
+	}
+
+	bucketURL := s.bucketURL(bucket)
+	start := time.Now()
+	status, size, header, body, err := s.http.Request(ctx, s.listURL(bucketURL), lib.RequestOptions{ReturnBody: true})
+	duration := time.Since(start)
+	if err != nil {
+		return err
+	}
+	if status == nil {
+		return nil
+	}
+
+	result := Result{
+		Endpoint:   s.options.Endpoint,
+		Bucket:     bucket,
+		URL:        bucketURL,
+		Word:       word,
+		StatusCode: *status,
+		Size:       size,
+		Header:     header,
+		Duration:   duration,
+	}
+	switch *status {
+	case http.StatusOK:
+		result.Found = true
+		result.Access = AccessListable
+		keys, err := parseKeys(body)
+		if err != nil {
+			return fmt.Errorf("could not parse bucket listing of %s: %w", bucketURL, err)
+		}
+		if len(keys) > s.options.MaxFiles {
+			keys = keys[:s.options.MaxFiles]
+		}
+		result.Keys = keys
+	case http.StatusForbidden:
+		result.Found = true
+		result.Access = AccessDenied
+	case http.StatusMovedPermanently, http.StatusTemporaryRedirect:
+		result.Found = true
+		result.Access = AccessOtherRegion
+	}
+
+	if result.Found || s.globalopts.Verbose {
+		results <- result
+	}
+	return nil
+}
+
+// bucketURL 根据配置拼接存储桶的地址
+func (s *GobusterS3) bucketURL(bucket string) string {
+	u := *s.endpoint
+	if s.options.PathStyle {
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/" + bucket + "/"
+	} else {
+		u.Host = bucket + "." + u.Host
+		u.Path = "/"
+	}
+	return u.String()
+}
+
+// listURL 列出存储桶的请求地址,不需要列出文件时只请求一个
+func (s *GobusterS3) listURL(bucketURL string) string {
+	maxKeys := s.options.MaxFiles
+	if maxKeys <= 0 {
+		maxKeys = 1
+	}
+	return fmt.Sprintf("%s?max-keys=%d", bucketURL, maxKeys)
+}
+
+// parseKeys 从ListBucketResult中取出文件名
+func parseKeys(body []byte) ([]string, error) {
+	var list listBucketResult
+	if err := xml.Unmarshal(body, &list); err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(list.Contents))
+	for _, c := range list.Contents {
+		keys = append(keys, c.Key)
+	}
+	return keys, nil
+}
+
+func (s *GobusterS3) GetConfigString() (string, error) {
+	var buf bytes.Buffer
+	bw := bufio.NewWriter(&buf)
+	tw := tabwriter.NewWriter(bw, 0, 5, 3, ' ', 0)
+	o := s.options
+
+	if _, err := fmt.Fprintf(tw, "[+] Endpoint:\t%s\n", o.Endpoint); err != nil {
+		return "", err
+	}
+
+	if o.PathStyle {
+		if _, err := fmt.Fprintf(tw, "[+] Path Style:\ttrue\n"); err != nil {
+			return "", err
+		}
+	}
+
+	if _, err := fmt.Fprintf(tw, "[+] Threads:\t%d\n", s.globalopts.Threads); err != nil {
+		return "", err
+	}
+
+	if s.globalopts.Delay > 0 {
+		if _, err := fmt.Fprintf(tw, "[+] Delay:\t%s\n", s.globalopts.Delay); err != nil {
+			return "", err
+		}
+	}
+
+	wordlist := "stdin (pipe)"
+	if s.globalopts.Wordlist != "-" {
+		wordlist = s.globalopts.Wordlist
+	}
+	if _, err := fmt.Fprintf(tw, "[+] Wordlist:\t%s\n", wordlist); err != nil {
+		return "", err
+	}
+
+	if s.globalopts.PatternFile != "" {
+		if _, err := fmt.Fprintf(tw, "[+] Patterns:\t%s (%d entries)\n", s.globalopts.PatternFile, len(s.globalopts.Patterns)); err != nil {
+			return "", err
+		}
+	}
+
+	if o.MaxFiles > 0 {
+		if _, err := fmt.Fprintf(tw, "[+] Max files to list:\t%d\n", o.MaxFiles); err != nil {
+			return "", err
+		}
+	}
+
+	if err := o.WriteHTTPConfig(tw); err != nil {
+		return "", err
+	}
+
+	if s.globalopts.Verbose {
+		if _, err := fmt.Fprintf(tw, "[+] Verbose:\ttrue\n"); err != nil {
+			return "", err
+		}
+	}
+
+	if _, err := fmt.Fprintf(tw, "[+] Timeout:\t%s\n", o.Timeout.String()); err != nil {
+		return "", err
+	}
+
+	if err := tw.Flush(); err != nil {
+		return "", fmt.Errorf("error on tostring: %w", err)
+	}
+
+	if err := bw.Flush(); err != nil {
+		return "", fmt.Errorf("error on tostring: %w", err)
+	}
+
+	return strings.TrimSpace(buf.String()), nil
+}
@@ -0,0 +1,15 @@
+package s3
+
+import "buster/lib"
+
+// OptionsS3 s3模式的配置
+type OptionsS3 struct {
+	lib.BasicHTTPOptions
+	Endpoint  string //对象存储的地址,默认为AWS
+	PathStyle bool   //使用endpoint/bucket形式的地址,否则使用bucket.endpoint
+	MaxFiles  int    //存储桶可以列出时最多显示的文件数,0表示不列出
+}
+
+func NewOptionsS3() *OptionsS3 {
+	return &OptionsS3{}
+}
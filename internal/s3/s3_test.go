@@ -0,0 +1,134 @@
+package s3
+
+import (
+	"buster/lib"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestServer 路径风格的s3服务:files可以列出,secret拒绝访问,moved位于其他区域,其他存储桶不存在
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bucket := strings.Trim(r.URL.Path, "/")
+		switch bucket {
+		case "files":
+			fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<ListBucketResult><Name>files</Name><Contents><Key>a.txt</Key></Contents><Contents><Key>b/c.png</Key></Contents><Contents><Key>d.zip</Key></Contents></ListBucketResult>`)
+		case "secret":
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprintf(w, `<Error><Code>AccessDenied</Code></Error>`)
+		case "moved":
+			w.WriteHeader(http.StatusMovedPermanently)
+			fmt.Fprintf(w, `<Error><Code>PermanentRedirect</Code></Error>`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, `<Error><Code>NoSuchBucket</Code></Error>`)
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func newTestPlugin(t *testing.T, endpoint string) *GobusterS3 {
+	t.Helper()
+	globalopts := lib.NewOptions()
+	globalopts.Verbose = true
+	opts := NewOptionsS3()
+	opts.Endpoint = endpoint
+	opts.PathStyle = true
+	opts.MaxFiles = 2
+	opts.Timeout = time.Second
+	s, err := NewGobusterS3(globalopts, opts)
+	if err != nil {
+		t.Fatalf("NewGobusterS3: %v", err)
+	}
+	return s
+}
+
+func run(t *testing.T, s *GobusterS3, word string) Result {
+	t.Helper()
+	results := make(chan lib.Result, 1)
+	if err := s.Run(context.Background(), word, results); err != nil {
+		t.Fatalf("Run(%s): %v", word, err)
+	}
+	select {
+	case r := <-results:
+		return r.(Result)
+	default:
+		t.Fatalf("Run(%s) returned no result", word)
+		return Result{}
+	}
+}
+
+func TestRun(t *testing.T) {
+	ts := newTestServer(t)
+	s := newTestPlugin(t, ts.URL)
+	if err := s.PreRun(context.Background()); err != nil {
+		t.Fatalf("PreRun: %v", err)
+	}
+
+	r := run(t, s, "Files")
+	if !r.Found || r.Access != AccessListable || r.Bucket != "files" {
+		t.Fatalf("files: got %+v, want a listable bucket", r)
+	}
+	if r.URL != ts.URL+"/files/" {
+		t.Errorf("files: got url %s, want %s/files/", r.URL, ts.URL)
+	}
+	if len(r.Keys) != 2 || r.Keys[0] != "a.txt" || r.Keys[1] != "b/c.png" {
+		t.Errorf("files: got keys %v, want the first 2 keys", r.Keys)
+	}
+
+	tests := []struct {
+		word   string
+		found  bool
+		access string
+	}{
+		{"secret", true, AccessDenied},
+		{"moved", true, AccessOtherRegion},
+		{"missing", false, ""},
+	}
+	for _, tt := range tests {
+		r := run(t, s, tt.word)
+		if r.Found != tt.found || r.Access != tt.access {
+			t.Errorf("%s: got found=%v access=%q, want found=%v access=%q", tt.word, r.Found, r.Access, tt.found, tt.access)
+		}
+	}
+}
+
+func TestRunInvalidName(t *testing.T) {
+	ts := newTestServer(t)
+	s := newTestPlugin(t, ts.URL)
+	//不符合规则的名称不发送请求
+	for _, word := range []string{"ab", "-files", "Files_1", strings.Repeat("a", 64)} {
+		results := make(chan lib.Result, 1)
+		if err := s.Run(context.Background(), word, results); err != nil {
+			t.Fatalf("Run(%s): %v", word, err)
+		}
+		if len(results) != 0 {
+			t.Errorf("%s: got %+v, want no result", word, <-results)
+		}
+	}
+}
+
+func TestPreRun(t *testing.T) {
+	//对任意存储桶都返回拒绝访问的地址
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer ts.Close()
+	s := newTestPlugin(t, ts.URL)
+	if err := s.PreRun(context.Background()); err == nil {
+		t.Fatal("PreRun: got nil, want an error for an endpoint that reports every bucket")
+	}
+
+	ts.Close()
+	if err := s.PreRun(context.Background()); err == nil {
+		t.Fatal("PreRun: got nil, want an error for an unreachable endpoint")
+	}
+}
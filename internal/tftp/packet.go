@@ -0,0 +1,112 @@
+package tftp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+)
+
+// tftp的操作码(RFC 1350, RFC 2347)
+const (
+	opRRQ   = 1
+	opDATA  = 3
+	opACK   = 4
+	opERROR = 5
+	opOACK  = 6
+)
+
+// tftp的错误码
+const (
+	errUndefined      = 0
+	errFileNotFound   = 1
+	errAccessViolated = 2
+)
+
+// blockSize 没有协商blksize时每个数据块的大小
+const blockSize = 512
+
+// packet 服务器返回的数据包
+type packet struct {
+	opcode  uint16
+	block   uint16            //DATA中的块编号
+	data    []byte            //DATA中的数据
+	code    uint16            //ERROR中的错误码
+	message string            //ERROR中的错误信息
+	options map[string]string //OACK中服务器接受的选项
+}
+
+// rrqPacket 构造读请求,请求tsize选项以便服务器在OACK中返回文件大小
+func rrqPacket(filename string) []byte {
+	var b bytes.Buffer
+	_ = binary.Write(&b, binary.BigEndian, uint16(opRRQ))
+	for _, s := range []string{filename, "octet", "tsize", "0"} {
+		b.WriteString(s)
+		b.WriteByte(0)
+	}
+	return b.Bytes()
+}
+
+// ackPacket 确认收到的数据块
+func ackPacket(block uint16) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint16(b, opACK)
+	binary.BigEndian.PutUint16(b[2:], block)
+	return b
+}
+
+// errorPacket 用于中止传输
+func errorPacket(code uint16, message string) []byte {
+	b := make([]byte, 4, 5+len(message))
+	binary.BigEndian.PutUint16(b, opERROR)
+	binary.BigEndian.PutUint16(b[2:], code)
+	b = append(b, message...)
+	return append(b, 0)
+}
+
+// parsePacket 解析服务器返回的数据包
+func parsePacket(b []byte) (*packet, error) {
+	if len(b) < 2 {
+		return nil, fmt.Errorf("short tftp packet")
+	}
+	p := &packet{opcode: binary.BigEndian.Uint16(b)}
+	switch p.opcode {
+	case opDATA:
+		if len(b) < 4 {
+			return nil, fmt.Errorf("short tftp data packet")
+		}
+		p.block = binary.BigEndian.Uint16(b[2:])
+		p.data = b[4:]
+	case opERROR:
+		if len(b) < 4 {
+			return nil, fmt.Errorf("short tftp error packet")
+		}
+		p.code = binary.BigEndian.Uint16(b[2:])
+		p.message = string(bytes.TrimRight(b[4:], "\x00"))
+	case opOACK:
+		fields := bytes.Split(bytes.TrimRight(b[2:], "\x00"), []byte{0})
+		if len(fields)%2 != 0 {
+			return nil, fmt.Errorf("malformed tftp option acknowledgement")
+		}
+		p.options = make(map[string]string)
+		for i := 0; i < len(fields); i += 2 {
+			p.options[string(bytes.ToLower(fields[i]))] = string(fields[i+1])
+		}
+	default:
+		return nil, fmt.Errorf("unexpected tftp opcode %d", p.opcode)
+	}
+	return p, nil
+}
+
+// tsize 从OACK中取出文件大小
+func (p *packet) tsize() (int64, bool) {
+	v, ok := p.options["tsize"]
+	if !ok {
+		return 0, false
+	}
+	size, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || size < 0 {
+		return 0, false
+	}
+	return size, true
+}
@@ -0,0 +1,39 @@
+package tftp
+
+import (
+	"buster/lib"
+	"time"
+)
+
+// PluginName 结果中标记的插件名称
+const PluginName = "tftp"
+
+type Result struct {
+	Server    string
+	Found     bool
+	Filename  string
+	Word      string
+	Size      int64
+	SizeKnown bool   //服务器不支持tsize并且文件超过一个数据块时无法得知大小
+	Message   string //服务器返回的错误信息
+	Duration  time.Duration
+}
+
+// Fields 实现result接口
+func (r Result) Fields() lib.ResultFields {
+	f := lib.ResultFields{
+		Plugin:   PluginName,
+		Target:   r.Server,
+		Found:    r.Found,
+		Path:     r.Filename,
+		Word:     r.Word,
+		Duration: r.Duration,
+		Info:     r.Message,
+	}
+	if r.SizeKnown {
+		f.Size = r.Size
+	} else if r.Found && r.Message == "" {
+		f.Info = "size unknown"
+	}
+	return f
+}
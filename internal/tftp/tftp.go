@@ -0,0 +1,251 @@
+package tftp
+
+import (
+	"bufio"
+	"buster/lib"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"net"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// GobusterTFTP tftp模式的核心实现,实现plugin接口,对每个单词发送读请求判断文件是否存在
+type GobusterTFTP struct {
+	options    *OptionsTFTP
+	globalopts *lib.Options
+	server     *net.UDPAddr
+	missCode   uint16 //服务器对不存在的文件返回的错误码,在PreRun中确定
+}
+
+// NewGobusterTFTP 根据全局配置和tftp配置生成GobusterTFTP(实现了plugin接口)
+func NewGobusterTFTP(globalopts *lib.Options, opts *OptionsTFTP) (*GobusterTFTP, error) {
+	if globalopts == nil {
+		return nil, fmt.Errorf("please provide valid global options")
+	}
+
+	if opts == nil {
+		return nil, fmt.Errorf("please provide valid plugin options")
+	}
+
+	server, err := net.ResolveUDPAddr("udp", opts.Server)
+	if err != nil {
+		return nil, fmt.Errorf("invalid server %s: %w", opts.Server, err)
+	}
+
+	g := GobusterTFTP{
+		options:    opts,
+		globalopts: globalopts,
+		server:     server,
+		missCode:   errFileNotFound,
+	}
+	return &g, nil
+}
+
+func (t *GobusterTFTP) Name() string {
+	return "TFTP enumeration"
+}
+
+// RequestPerRun 每个单词只发送一次读请求
+func (t *GobusterTFTP) RequestPerRun() int {
+	return 1
+}
+
+// PreRun 请求一个不存在的文件,确认服务器可以访问并且不会对任意文件名返回数据,
+// 同时记录服务器对不存在的文件返回的错误码
+func (t *GobusterTFTP) PreRun(ctx context.Context) error {
+	name := uuid.New().String()
+	p, conn, err := t.request(ctx, name)
+	if err != nil {
+		return fmt.Errorf("unable to connect to %s: %w", t.options.Server, err)
+	}
+	if p == nil {
+		return ctx.Err()
+	}
+	defer conn.close()
+
+	if p.opcode != opERROR {
+		conn.send(errorPacket(errUndefined, "transfer cancelled"))
+		return fmt.Errorf("the server %s returned a file for the non existing file %s", t.options.Server, name)
+	}
+	t.missCode = p.code
+	return nil
+}
+
+// Run 对word发送读请求,服务器开始传输(或确认选项)时视为文件存在,随后中止传输
+func (t *GobusterTFTP) Run(ctx context.Context, word string, results chan<- lib.Result) error {
+	start := time.Now()
+	p, conn, err := t.request(ctx, word)
+	duration := time.Since(start)
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		return nil
+	}
+	defer conn.close()
+
+	result := Result{
+		Server:   t.options.Server,
+		Filename: word,
+		Word:     word,
+		Duration: duration,
+	}
+	switch p.opcode {
+	case opOACK:
+		result.Found = true
+		result.Size, result.SizeKnown = p.tsize()
+		conn.send(errorPacket(errUndefined, "transfer cancelled"))
+	case opDATA:
+		result.Found = true
+		//第一个数据块不足一个块时即为整个文件
+		if p.block == 1 && len(p.data) < blockSize {
+			result.Size, result.SizeKnown = int64(len(p.data)), true
+			conn.send(ackPacket(p.block))
+		} else {
+			conn.send(errorPacket(errUndefined, "transfer cancelled"))
+		}
+	case opERROR:
+		//文件存在但是没有读取权限,服务器对不存在的文件也返回该错误码时无法区分
+		result.Found = p.code == errAccessViolated && p.code != t.missCode
+		if p.code != errFileNotFound && p.code != t.missCode {
+			result.Message = p.message
+		}
+	}
+
+	if result.Found || t.globalopts.Verbose {
+		results <- result
+	}
+	return nil
+}
+
+// transfer 一次读请求使用的连接,服务器从新的端口(TID)进行响应
+type transfer struct {
+	conn *net.UDPConn
+	peer *net.UDPAddr
+	done chan struct{}
+}
+
+func (c *transfer) send(b []byte) {
+	_, _ = c.conn.WriteToUDP(b, c.peer)
+}
+
+func (c *transfer) close() {
+	close(c.done)
+	c.conn.Close()
+}
+
+// request 发送读请求并等待服务器的第一个响应,超时时间为Timeout,ctx取消时返回nil
+func (t *GobusterTFTP) request(ctx context.Context, filename string) (*packet, *transfer, error) {
+	conn, err := net.ListenUDP("udp", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	c := &transfer{conn: conn, done: make(chan struct{})}
+	//ctx取消时关闭连接,结束阻塞的读取
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-c.done:
+		}
+	}()
+
+	if err := conn.SetDeadline(time.Now().Add(t.options.Timeout)); err != nil {
+		c.close()
+		return nil, nil, err
+	}
+	if _, err := conn.WriteToUDP(rrqPacket(filename), t.server); err != nil {
+		c.close()
+		if ctx.Err() != nil {
+			return nil, nil, nil //ctx取消不做处理
+		}
+		return nil, nil, err
+	}
+
+	buf := make([]byte, 4+blockSize+1)
+	for {
+		n, from, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			c.close()
+			if ctx.Err() != nil {
+				return nil, nil, nil //ctx取消不做处理
+			}
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Timeout() {
+				return nil, nil, fmt.Errorf("no response within %s", t.options.Timeout)
+			}
+			return nil, nil, err
+		}
+		//忽略其他主机发来的数据包
+		if !from.IP.Equal(t.server.IP) {
+			continue
+		}
+		p, err := parsePacket(buf[:n])
+		if err != nil {
+			c.close()
+			return nil, nil, err
+		}
+		c.peer = from
+		return p, c, nil
+	}
+}
+
+func (t *GobusterTFTP) GetConfigString() (string, error) {
+	var buf bytes.Buffer
+	bw := bufio.NewWriter(&buf)
+	tw := tabwriter.NewWriter(bw, 0, 5, 3, ' ', 0)
+	o := t.options
+
+	if _, err := fmt.Fprintf(tw, "[+] Server:\t%s\n", o.Server); err != nil {
+		return "", err
+	}
+
+	if _, err := fmt.Fprintf(tw, "[+] Threads:\t%d\n", t.globalopts.Threads); err != nil {
+		return "", err
+	}
+
+	if t.globalopts.Delay > 0 {
+		if _, err := fmt.Fprintf(tw, "[+] Delay:\t%s\n", t.globalopts.Delay); err != nil {
+			return "", err
+		}
+	}
+
+	if _, err := fmt.Fprintf(tw, "[+] Timeout:\t%s\n", o.Timeout.String()); err != nil {
+		return "", err
+	}
+
+	wordlist := "stdin (pipe)"
+	if t.globalopts.Wordlist != "-" {
+		wordlist = t.globalopts.Wordlist
+	}
+	if _, err := fmt.Fprintf(tw, "[+] Wordlist:\t%s\n", wordlist); err != nil {
+		return "", err
+	}
+
+	if t.globalopts.PatternFile != "" {
+		if _, err := fmt.Fprintf(tw, "[+] Patterns:\t%s (%d entries)\n", t.globalopts.PatternFile, len(t.globalopts.Patterns)); err != nil {
+			return "", err
+		}
+	}
+
+	if t.globalopts.Verbose {
+		if _, err := fmt.Fprintf(tw, "[+] Verbose:\ttrue\n"); err != nil {
+			return "", err
+		}
+	}
+
+	if err := tw.Flush(); err != nil {
+		return "", fmt.Errorf("error on tostring: %w", err)
+	}
+
+	if err := bw.Flush(); err != nil {
+		return "", fmt.Errorf("error on tostring: %w", err)
+	}
+
+	return strings.TrimSpace(buf.String()), nil
+}
@@ -0,0 +1,13 @@
+package tftp
+
+import "time"
+
+// OptionsTFTP tftp模式的配置
+type OptionsTFTP struct {
+	Server  string //服务器地址,没有指定端口时使用69
+	Timeout time.Duration
+}
+
+func NewOptionsTFTP() *OptionsTFTP {
+	return &OptionsTFTP{}
+}
@@ -0,0 +1,195 @@
+package tftp
+
+import (
+	"buster/lib"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testServer 进程内的tftp服务器,对每个读请求从新的端口返回第一个数据包
+type testServer struct {
+	conn     *net.UDPConn
+	files    map[string][]byte
+	denied   map[string]bool
+	oack     bool   //是否支持tsize选项
+	missCode uint16 //文件不存在时返回的错误码
+	anyFile  bool   //对任意文件名都返回数据
+}
+
+// newTestServer 启动服务器,setup不为nil时在开始响应之前修改服务器的配置
+func newTestServer(t *testing.T, setup func(*testServer)) *testServer {
+	t.Helper()
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("could not listen: %v", err)
+	}
+	s := &testServer{
+		conn: conn,
+		files: map[string][]byte{
+			"small.txt": []byte("0123456789"),
+			"big.bin":   bytes.Repeat([]byte{'x'}, 2000),
+		},
+		denied:   map[string]bool{"secret": true},
+		missCode: errFileNotFound,
+	}
+	if setup != nil {
+		setup(s)
+	}
+	t.Cleanup(func() { conn.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *testServer) addr() string {
+	return s.conn.LocalAddr().String()
+}
+
+func (s *testServer) serve() {
+	buf := make([]byte, 1024)
+	for {
+		n, from, err := s.conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		if n < 2 || binary.BigEndian.Uint16(buf) != opRRQ {
+			continue
+		}
+		fields := strings.Split(string(buf[2:n]), "\x00")
+		if reply := s.reply(fields[0], len(fields) > 3); reply != nil {
+			go s.send(reply, from)
+		}
+	}
+}
+
+// send 从新的端口发送响应,并读取客户端的确认或中止
+func (s *testServer) send(b []byte, to *net.UDPAddr) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	_, _ = conn.WriteToUDP(b, to)
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, _, _ = conn.ReadFromUDP(make([]byte, 516))
+}
+
+func (s *testServer) reply(filename string, options bool) []byte {
+	data, ok := s.files[filename]
+	switch {
+	case s.denied[filename]:
+		return errorPacket(errAccessViolated, "Permission denied")
+	case !ok && !s.anyFile:
+		return errorPacket(s.missCode, "File not found")
+	case s.oack && options:
+		var b bytes.Buffer
+		_ = binary.Write(&b, binary.BigEndian, uint16(opOACK))
+		b.WriteString("tsize\x00" + strconv.Itoa(len(data)) + "\x00")
+		return b.Bytes()
+	}
+	if len(data) > blockSize {
+		data = data[:blockSize]
+	}
+	b := make([]byte, 4, 4+len(data))
+	binary.BigEndian.PutUint16(b, opDATA)
+	binary.BigEndian.PutUint16(b[2:], 1)
+	return append(b, data...)
+}
+
+func newTestPlugin(t *testing.T, s *testServer) *GobusterTFTP {
+	t.Helper()
+	globalopts := lib.NewOptions()
+	globalopts.Verbose = true
+	opts := NewOptionsTFTP()
+	opts.Server = s.addr()
+	opts.Timeout = 500 * time.Millisecond
+	g, err := NewGobusterTFTP(globalopts, opts)
+	if err != nil {
+		t.Fatalf("NewGobusterTFTP: %v", err)
+	}
+	return g
+}
+
+func run(t *testing.T, g *GobusterTFTP, word string) Result {
+	t.Helper()
+	results := make(chan lib.Result, 1)
+	if err := g.Run(context.Background(), word, results); err != nil {
+		t.Fatalf("Run(%s): %v", word, err)
+	}
+	select {
+	case r := <-results:
+		return r.(Result)
+	default:
+		t.Fatalf("Run(%s) returned no result", word)
+		return Result{}
+	}
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		word      string
+		oack      bool
+		found     bool
+		size      int64
+		sizeKnown bool
+	}{
+		{"small.txt", true, true, 10, true},
+		{"big.bin", true, true, 2000, true},
+		{"small.txt", false, true, 10, true},
+		{"big.bin", false, true, 0, false},
+		{"secret", true, true, 0, false},
+		{"missing", true, false, 0, false},
+	}
+	for _, tt := range tests {
+		oack := tt.oack
+		s := newTestServer(t, func(s *testServer) { s.oack = oack })
+		g := newTestPlugin(t, s)
+		if err := g.PreRun(context.Background()); err != nil {
+			t.Fatalf("PreRun: %v", err)
+		}
+		r := run(t, g, tt.word)
+		if r.Found != tt.found || r.Size != tt.size || r.SizeKnown != tt.sizeKnown {
+			t.Errorf("%s (oack %v): got found=%v size=%d known=%v, want found=%v size=%d known=%v",
+				tt.word, tt.oack, r.Found, r.Size, r.SizeKnown, tt.found, tt.size, tt.sizeKnown)
+		}
+	}
+}
+
+func TestPreRun(t *testing.T) {
+	//对任意文件名都返回数据的服务器
+	s := newTestServer(t, func(s *testServer) { s.anyFile = true })
+	g := newTestPlugin(t, s)
+	if err := g.PreRun(context.Background()); err == nil {
+		t.Fatal("PreRun: got nil, want an error for a server that returns every file")
+	}
+
+	//不响应的服务器
+	s = newTestServer(t, nil)
+	g = newTestPlugin(t, s)
+	s.conn.Close()
+	if err := g.PreRun(context.Background()); err == nil {
+		t.Fatal("PreRun: got nil, want an error for a server that does not respond")
+	}
+}
+
+func TestPreRunAccessViolation(t *testing.T) {
+	//服务器对不存在的文件也返回access violation时,该错误码不能视为文件存在
+	s := newTestServer(t, func(s *testServer) { s.missCode = errAccessViolated })
+	g := newTestPlugin(t, s)
+	if err := g.PreRun(context.Background()); err != nil {
+		t.Fatalf("PreRun: %v", err)
+	}
+	for _, word := range []string{"secret", "missing"} {
+		if r := run(t, g, word); r.Found {
+			t.Errorf("%s: got %+v, want not found", word, r)
+		}
+	}
+	if r := run(t, g, "small.txt"); !r.Found {
+		t.Errorf("small.txt: got %+v, want found", r)
+	}
+}
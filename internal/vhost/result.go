@@ -0,0 +1,36 @@
+package vhost
+
+import (
+	"buster/lib"
+	"net/http"
+	"time"
+)
+
+// PluginName 结果中标记的插件名称
+const PluginName = "vhost"
+
+type Result struct {
+	Target     string
+	Found      bool
+	Vhost      string
+	Word       string
+	StatusCode int
+	Size       int64
+	Header     http.Header
+	Duration   time.Duration
+}
+
+// Fields 实现result接口
+func (r Result) Fields() lib.ResultFields {
+	return lib.ResultFields{
+		Plugin:     PluginName,
+		Target:     r.Target,
+		Found:      r.Found,
+		Host:       r.Vhost,
+		Word:       r.Word,
+		StatusCode: r.StatusCode,
+		Size:       r.Size,
+		Header:     r.Header,
+		Duration:   r.Duration,
+	}
+}
@@ -0,0 +1,258 @@
+package vhost
+
+import (
+	"bufio"
+	"buster/helper"
+	"buster/lib"
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"net/url"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// baseline 记录某个vhost的响应特征,用于和爆破结果进行比较
+type baseline struct {
+	statusCode int
+	size       int64
+}
+
+// GobusterVhost vhost模式的核心实现,实现plugin接口,向同一个url发起请求并替换Host头
+type GobusterVhost struct {
+	options    *OptionsVhost
+	globalopts *lib.Options
+	http       *lib.HTTPClient
+	matcher    *lib.ResponseMatcher
+	domain     string
+	baselines  []baseline
+}
+
+// NewGobusterVhost 根据全局配置和vhost配置生成GobusterVhost(实现了plugin接口)
+func NewGobusterVhost(globalopts *lib.Options, opts *OptionsVhost) (*GobusterVhost, error) {
+	if globalopts == nil {
+		return nil, fmt.Errorf("please provide valid global options")
+	}
+
+	if opts == nil {
+		return nil, fmt.Errorf("please provide valid plugin options")
+	}
+
+	g := GobusterVhost{
+		options:    opts,
+		globalopts: globalopts,
+	}
+
+	m, err := lib.NewResponseMatcher(opts.Matchers)
+	if err != nil {
+		return nil, err
+	}
+	g.matcher = m
+
+	h, err := lib.NewHTTPClient(&opts.HTTPOptions)
+	if err != nil {
+		return nil, err
+	}
+	g.http = h
+	return &g, nil
+}
+
+func (v *GobusterVhost) Name() string {
+	return "VHOST enumeration"
+}
+
+// RequestPerRun 每个单词只发起一次请求
+func (v *GobusterVhost) RequestPerRun() int {
+	return 1
+}
+
+// PreRun 分别请求默认的vhost和一个不存在的vhost,记录其响应作为基准
+func (v *GobusterVhost) PreRun(ctx context.Context) error {
+	if !strings.HasSuffix(v.options.URL, "/") {
+		v.options.URL = v.options.URL + "/"
+	}
+
+	u, err := url.Parse(v.options.URL)
+	if err != nil {
+		return fmt.Errorf("invalid url %s: %w", v.options.URL, err)
+	}
+	if v.options.Domain != "" {
+		v.domain = v.options.Domain
+	} else {
+		v.domain = u.Hostname()
+	}
+
+	//默认vhost
+	status, size, _, _, err := v.http.Request(ctx, v.options.URL, lib.RequestOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to connect to %s: %w", v.options.URL, err)
+	}
+	if status == nil {
+		return ctx.Err()
+	}
+	v.baselines = append(v.baselines, baseline{statusCode: *status, size: size})
+
+	//不存在的vhost
+	status, size, _, _, err = v.http.Request(ctx, v.options.URL, lib.RequestOptions{Host: v.hostname(uuid.New().String())})
+	if err != nil {
+		return fmt.Errorf("unable to connect to %s: %w", v.options.URL, err)
+	}
+	if status == nil {
+		return ctx.Err()
+	}
+	v.baselines = append(v.baselines, baseline{statusCode: *status, size: size})
+
+	return nil
+}
+
+// Run 使用word构造Host头发起请求,响应与所有基准都不同时视为发现
+func (v *GobusterVhost) Run(ctx context.Context, word string, results chan<- lib.Result) error {
+	host := v.hostname(word)
+	start := time.Now()
+	status, size, header, body, err := v.http.Request(ctx, v.options.URL, lib.RequestOptions{Host: host, ReturnBody: v.matcher.NeedsBody()})
+	duration := time.Since(start)
+	if err != nil {
+		return err
+	}
+	if status == nil {
+		return nil
+	}
+
+	found := !helper.SliceContains(v.options.ExcludeLength, int(size)) &&
+		v.matcher.Allow(&lib.MatchResponse{StatusCode: *status, Size: size, Header: header, Body: body})
+	for _, b := range v.baselines {
+		if b.statusCode == *status && b.size == size {
+			found = false
+			break
+		}
+	}
+
+	if found || v.globalopts.Verbose {
+		results <- Result{
+			Target:     v.options.URL,
+			Found:      found,
+			Vhost:      host,
+			Word:       word,
+			StatusCode: *status,
+			Size:       size,
+			Header:     header,
+			Duration:   duration,
+		}
+	}
+	return nil
+}
+
+// hostname 根据配置返回请求使用的Host
+func (v *GobusterVhost) hostname(word string) string {
+	if v.options.AppendDomain {
+		return fmt.Sprintf("%s.%s", word, v.domain)
+	}
+	return word
+}
+
+func (v *GobusterVhost) GetConfigString() (string, error) {
+	var buf bytes.Buffer
+	bw := bufio.NewWriter(&buf)
+	tw := tabwriter.NewWriter(bw, 0, 5, 3, ' ', 0)
+	o := v.options
+
+	if _, err := fmt.Fprintf(tw, "[+] Url:\t%s\n", o.URL); err != nil {
+		return "", err
+	}
+
+	if _, err := fmt.Fprintf(tw, "[+] Method:\t%s\n", o.Method); err != nil {
+		return "", err
+	}
+
+	if _, err := fmt.Fprintf(tw, "[+] Threads:\t%d\n", v.globalopts.Threads); err != nil {
+		return "", err
+	}
+
+	if v.globalopts.Delay > 0 {
+		if _, err := fmt.Fprintf(tw, "[+] Delay:\t%s\n", v.globalopts.Delay); err != nil {
+			return "", err
+		}
+	}
+
+	wordlist := "stdin (pipe)"
+	if v.globalopts.Wordlist != "-" {
+		wordlist = v.globalopts.Wordlist
+	}
+	if _, err := fmt.Fprintf(tw, "[+] Wordlist:\t%s\n", wordlist); err != nil {
+		return "", err
+	}
+
+	if v.globalopts.PatternFile != "" {
+		if _, err := fmt.Fprintf(tw, "[+] Patterns:\t%s (%d entries)\n", v.globalopts.PatternFile, len(v.globalopts.Patterns)); err != nil {
+			return "", err
+		}
+	}
+
+	if o.AppendDomain {
+		if _, err := fmt.Fprintf(tw, "[+] Append Domain:\ttrue\n"); err != nil {
+			return "", err
+		}
+	}
+
+	if o.Domain != "" {
+		if _, err := fmt.Fprintf(tw, "[+] Domain:\t%s\n", o.Domain); err != nil {
+			return "", err
+		}
+	}
+
+	if len(o.ExcludeLength) > 0 {
+		if _, err := fmt.Fprintf(tw, "[+] Exclude Length:\t%s\n", helper.JoinIntSlice(o.ExcludeLength)); err != nil {
+			return "", err
+		}
+	}
+
+	if m := o.Matchers.Stringify(); m != "" {
+		if _, err := fmt.Fprintf(tw, "[+] Matchers:\t%s\n", m); err != nil {
+			return "", err
+		}
+	}
+
+	if err := o.WriteHTTPConfig(tw); err != nil {
+		return "", err
+	}
+
+	if o.Cookies != "" {
+		if _, err := fmt.Fprintf(tw, "[+] Cookies:\t%s\n", o.Cookies); err != nil {
+			return "", err
+		}
+	}
+
+	if o.Username != "" {
+		if _, err := fmt.Fprintf(tw, "[+] Auth User:\t%s\n", o.Username); err != nil {
+			return "", err
+		}
+	}
+
+	if o.FollowRedirect {
+		if _, err := fmt.Fprintf(tw, "[+] Follow Redirect:\ttrue\n"); err != nil {
+			return "", err
+		}
+	}
+
+	if v.globalopts.Verbose {
+		if _, err := fmt.Fprintf(tw, "[+] Verbose:\ttrue\n"); err != nil {
+			return "", err
+		}
+	}
+
+	if _, err := fmt.Fprintf(tw, "[+] Timeout:\t%s\n", o.Timeout.String()); err != nil {
+		return "", err
+	}
+
+	if err := tw.Flush(); err != nil {
+		return "", fmt.Errorf("error on tostring: %w", err)
+	}
+
+	if err := bw.Flush(); err != nil {
+		return "", fmt.Errorf("error on tostring: %w", err)
+	}
+
+	return strings.TrimSpace(buf.String()), nil
+}
@@ -0,0 +1,16 @@
+package vhost
+
+import "buster/lib"
+
+// OptionsVhost vhost模式的配置
+type OptionsVhost struct {
+	lib.HTTPOptions
+	AppendDomain  bool
+	Domain        string
+	ExcludeLength []int
+	Matchers      lib.MatcherOptions
+}
+
+func NewOptionsVhost() *OptionsVhost {
+	return &OptionsVhost{}
+}
@@ -14,6 +14,9 @@ const (
 	VERSION = "3.1.0"
 )
 
+// FuzzKeyword 请求中需要被替换为单词的关键字
+const FuzzKeyword = "FUZZ"
+
 // DefaultUserAgent returns the default user agent to use in HTTP requests
 func DefaultUserAgent() string {
 	return fmt.Sprintf("gobuster/%s", VERSION)
@@ -126,16 +129,25 @@ func lineConut(r io.Reader) (int, error) {
 	}
 
 }
+
+// lineCounter 统计行数,与bufio.Scanner的分行方式保持一致(最后一行没有换行符时同样计入)
 func lineCounter(r io.Reader) (int, error) {
 	buf := make([]byte, 32*1024)
 	count := 0
 	lineSep := []byte{'\n'}
+	var last byte
 	for {
 		c, err := r.Read(buf)
 		count += bytes.Count(buf[:c], lineSep)
+		if c > 0 {
+			last = buf[c-1]
+		}
 
 		switch {
 		case errors.Is(err, io.EOF):
+			if last != 0 && last != '\n' {
+				count++
+			}
 			return count, nil
 		case err != nil:
 			return -1, err
@@ -1,14 +1,15 @@
 package lib
 
 import (
+	"bytes"
 	"context"
-	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 )
 
 type HTTPHeader struct {
@@ -26,33 +27,26 @@ type HTTPClient struct {
 	cookies          string
 	method           string
 	host             string
+	limiter          *RateLimiter
+	retry            retryPolicy
 }
 
 // RequestOptions is used to pass options to a single individual request
 type RequestOptions struct {
-	Host       string
-	Body       io.Reader
-	ReturnBody bool
+	Host            string
+	Body            io.Reader
+	ReturnBody      bool
+	ModifiedHeaders []HTTPHeader //仅对本次请求生效的header,会覆盖client中同名的header
+	Method          string       //仅对本次请求生效的method,为空时使用client的method
 }
 
 func NewHTTPClient(opt *HTTPOptions) (*HTTPClient, error) {
 	var client HTTPClient
 
-	//配置代理
-	var proxyURLFunc func(*http.Request) (*url.URL, error)
-	proxyURLFunc = http.ProxyFromEnvironment //设置默认代理
 	if opt == nil {
 		return nil, fmt.Errorf("options is nil")
 	}
 
-	if opt.Proxy != "" {
-		proxyURL, err := url.Parse(opt.Proxy)
-		if err != nil {
-			return nil, fmt.Errorf("proxy URL is invalid (%w)", err)
-		}
-		proxyURLFunc = http.ProxyURL(proxyURL)
-	}
-
 	//配置重定向(第一个参数是即将转发的请求,via是之前执行过的请求,默认的配置会判断via的长度来控制跳转次数)
 	var redirectFunc func(req *http.Request, via []*http.Request) error
 	if !opt.FollowRedirect {
@@ -63,17 +57,32 @@ func NewHTTPClient(opt *HTTPOptions) (*HTTPClient, error) {
 		redirectFunc = nil //跟随重定向,设置为nil,会使用默认的重定向策略(最多跟随10次)
 	}
 
+	tlsConfig, err := newTLSConfig(&opt.BasicHTTPOptions)
+	if err != nil {
+		return nil, err
+	}
+	transport, err := newTransport(&opt.BasicHTTPOptions, tlsConfig)
+	if err != nil {
+		return nil, err
+	}
 	client.client = &http.Client{
 		Timeout:       opt.Timeout,
 		CheckRedirect: redirectFunc,
-		Transport: &http.Transport{
-			Proxy:               proxyURLFunc,
-			MaxIdleConns:        100,
-			MaxIdleConnsPerHost: 100,
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: opt.NoTLSValidation,
-			},
-		}}
+		Transport:     transport,
+	}
+
+	if opt.Limiter != nil {
+		client.limiter = opt.Limiter
+	} else if opt.RateLimit > 0 {
+		client.limiter = NewRateLimiter(opt.RateLimit, opt.RateBurst)
+	}
+
+	client.retry = retryPolicy{
+		Attempts: opt.RetryAttempts,
+		Backoff:  opt.RetryBackoff,
+		Jitter:   opt.RetryJitter,
+		OnStatus: opt.RetryOnStatus,
+	}
 
 	client.username = opt.Username
 	client.password = opt.Password
@@ -94,10 +103,54 @@ func NewHTTPClient(opt *HTTPOptions) (*HTTPClient, error) {
 	return &client, nil
 }
 
-// Request 对目标发起http请求
+// Request 对目标发起http请求,遇到临时性的错误时按照配置进行重试
 func (client *HTTPClient) Request(ctx context.Context, fullURL string,
 	opts RequestOptions) (*int, int64, http.Header, []byte, error) {
-	resp, err := client.makeRequest(ctx, fullURL, opts.Host, opts.Body)
+	//重试时需要重新发送body,先将其缓存下来
+	var reqBody []byte
+	if opts.Body != nil && client.retry.Attempts > 0 {
+		b, err := io.ReadAll(opts.Body)
+		if err != nil {
+			return nil, 0, nil, nil, fmt.Errorf("could not read request body: %w", err)
+		}
+		reqBody = b
+	}
+
+	for attempt := 0; ; attempt++ {
+		if reqBody != nil {
+			opts.Body = bytes.NewReader(reqBody)
+		}
+		statusCode, length, header, body, err := client.request(ctx, fullURL, opts)
+		wait, retry := client.retry.delay(attempt, statusCode, header, err)
+		if !retry {
+			if err == nil {
+				if err := client.retry.exhausted(statusCode); err != nil {
+					return nil, 0, nil, nil, &url.Error{Op: client.requestMethod(opts), URL: fullURL, Err: err}
+				}
+			}
+			return statusCode, length, header, body, err
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, 0, nil, nil, nil //ctx取消不做处理
+		case <-timer.C:
+		}
+	}
+}
+
+// request 对目标发起一次http请求
+func (client *HTTPClient) request(ctx context.Context, fullURL string,
+	opts RequestOptions) (*int, int64, http.Header, []byte, error) {
+	//限速在所有worker之间共享,与线程数无关
+	if client.limiter != nil {
+		if err := client.limiter.Wait(ctx); err != nil {
+			return nil, 0, nil, nil, nil //ctx取消不做处理
+		}
+	}
+	resp, err := client.makeRequest(ctx, fullURL, opts)
 	if err != nil {
 		if errors.Is(ctx.Err(), context.Canceled) {
 			return nil, 0, nil, nil, nil //ctx取消不做处理
@@ -127,8 +180,16 @@ func (client *HTTPClient) Request(ctx context.Context, fullURL string,
 
 }
 
-func (client *HTTPClient) makeRequest(ctx context.Context, fullURL string, host string, data io.Reader) (*http.Response, error) {
-	req, err := http.NewRequestWithContext(ctx, client.method, fullURL, data)
+// requestMethod 单次请求指定的方法优先于全局配置
+func (client *HTTPClient) requestMethod(opts RequestOptions) string {
+	if opts.Method != "" {
+		return opts.Method
+	}
+	return client.method
+}
+
+func (client *HTTPClient) makeRequest(ctx context.Context, fullURL string, opts RequestOptions) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, client.requestMethod(opts), fullURL, opts.Body)
 	if err != nil {
 		return nil, err
 	}
@@ -136,8 +197,8 @@ func (client *HTTPClient) makeRequest(ctx context.Context, fullURL string, host
 		req.Header.Set("Cookie", client.cookies)
 	}
 
-	if host != "" {
-		req.Host = host
+	if opts.Host != "" {
+		req.Host = opts.Host
 	} else if client.host != "" {
 		req.Host = client.host
 	}
@@ -152,6 +213,13 @@ func (client *HTTPClient) makeRequest(ctx context.Context, fullURL string, host
 	for _, h := range client.headers {
 		req.Header.Set(h.Name, h.Value)
 	}
+	for _, h := range opts.ModifiedHeaders {
+		if h.Name == "Host" {
+			req.Host = h.Value
+			continue
+		}
+		req.Header.Set(h.Name, h.Value)
+	}
 
 	if client.username != "" {
 		req.SetBasicAuth(client.username, client.password)
@@ -3,9 +3,11 @@ package lib
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -16,11 +18,24 @@ import (
 type Gobuster struct {
 	Opts                           *Options
 	RequestExpected, RequestIssued int
+	ErrorCount                     int //Run返回错误的次数,同样由RequestCountMutex保护
+	RequestResumed                 int //恢复扫描时跳过的行对应的请求数(已计入RequestIssued),同样由RequestCountMutex保护
 	RequestCountMutex              sync.RWMutex
 	plugin                         GobusterPlugin //不同扫描模式实现的关键,以插件的形式体现
 	resultChan                     chan Result
 	errorChan                      chan error
 	LogInfo, LogError              *log.Logger
+	stdinWords                     []string //递归扫描时缓存的stdin字典
+	ResumeOffset                   int      //恢复扫描时需要跳过的字典行数
+	offset                         wordOffset
+	failedMutex                    sync.Mutex
+	failedWords                    StringSet //重试后仍然失败的单词
+}
+
+// wordEntry 字典中的一行及其行号
+type wordEntry struct {
+	index int
+	word  string
 }
 
 func NewGobuster(opts *Options, plugin GobusterPlugin) (*Gobuster, error) {
@@ -32,6 +47,7 @@ func NewGobuster(opts *Options, plugin GobusterPlugin) (*Gobuster, error) {
 		plugin:            plugin,
 		resultChan:        make(chan Result, 1),
 		errorChan:         make(chan error, 1),
+		failedWords:       NewStringSet(),
 		LogInfo:           log.New(os.Stdout, "", log.LstdFlags),
 		LogError:          log.New(os.Stdout, "[ERROR]", log.LstdFlags),
 	}, nil
@@ -44,13 +60,44 @@ func (g *Gobuster) Results() <-chan Result {
 func (g *Gobuster) Errors() <-chan error {
 	return g.errorChan
 }
+
+// Plugin 返回执行的插件
+func (g *Gobuster) Plugin() GobusterPlugin {
+	return g.plugin
+}
+
 func (g *Gobuster) GetConfigString() (string, error) {
 	return g.plugin.GetConfigString()
 }
 func (g *Gobuster) incrementRequest() {
+	g.incrementRequestBy(1)
+}
+
+// incrementRequestBy 按照执行Run的次数累加已发起的请求数
+func (g *Gobuster) incrementRequestBy(runs int) {
+	g.RequestCountMutex.Lock()
+	defer g.RequestCountMutex.Unlock()
+	g.RequestIssued += runs * g.plugin.RequestPerRun()
+}
+
+// incrementResumedBy 恢复扫描时跳过的行计入已发起的请求数,同时记录下来作为计算速度的基准
+func (g *Gobuster) incrementResumedBy(runs int) {
 	g.RequestCountMutex.Lock()
 	defer g.RequestCountMutex.Unlock()
-	g.RequestIssued += g.plugin.RequestPerRun()
+	g.RequestIssued += runs * g.plugin.RequestPerRun()
+	g.RequestResumed += runs * g.plugin.RequestPerRun()
+}
+
+// Progress 返回已发起和预期的请求数、恢复扫描时跳过的请求数以及错误数,
+// 插件按需发起的额外请求同时计入已发起和预期的请求数
+func (g *Gobuster) Progress() (issued, expected, resumed, errors int) {
+	extra := 0
+	if ep, ok := g.plugin.(ExtraRequestsPlugin); ok {
+		extra = ep.ExtraRequests()
+	}
+	g.RequestCountMutex.RLock()
+	defer g.RequestCountMutex.RUnlock()
+	return g.RequestIssued + extra, g.RequestExpected + extra, g.RequestResumed, g.ErrorCount
 }
 
 // Run 开始解析Wordlist,生产任务;并开启指定数量的worker进行并发执行
@@ -61,31 +108,130 @@ func (g *Gobuster) Run(ctx context.Context) error {
 	if err := g.plugin.PreRun(ctx); err != nil {
 		return err
 	}
+	if ep, ok := g.plugin.(PreRunErrorsPlugin); ok {
+		for _, err := range ep.PreRunErrors() {
+			g.errorChan <- err
+		}
+	}
 
+	if err := g.runWordlist(ctx, ""); err != nil {
+		return err
+	}
+	if pp, ok := g.plugin.(PostRunPlugin); ok && ctx.Err() == nil {
+		if err := pp.PostRun(ctx, g.resultChan); err != nil {
+			g.incrementError()
+			g.addFailedWords(err, "")
+			g.errorChan <- err
+		}
+	}
+
+	//插件支持递归时,对扫描过程中新发现的目标重新执行整个字典,直到没有新的目标
+	rp, ok := g.plugin.(RecursivePlugin)
+	if !ok || !rp.Recursive() {
+		return nil
+	}
+	for {
+		bases := rp.NewBases()
+		if len(bases) == 0 {
+			return nil
+		}
+		for _, base := range bases {
+			if ctx.Err() != nil {
+				return nil
+			}
+			//新目标检查失败(如存在通配)只跳过该目标,不影响整个任务
+			if err := rp.PreRunBase(ctx, base); err != nil {
+				g.errorChan <- err
+				continue
+			}
+			if err := g.runWordlist(ctx, base); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// RunWithHandlers 执行Run并在当前goroutine中依次处理结果和错误,不进行任何输出,适合作为库使用
+// onResult和onError可以为nil,Run本身返回的错误(如PreRun失败)作为返回值
+func (g *Gobuster) RunWithHandlers(ctx context.Context, onResult func(Result), onError func(error)) error {
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- g.Run(ctx)
+	}()
+
+	//Run结束时会关闭两个chan,两者都被关闭后才能返回
+	results, errors := g.Results(), g.Errors()
+	for results != nil || errors != nil {
+		select {
+		case r, ok := <-results:
+			if !ok {
+				results = nil
+				continue
+			}
+			if onResult != nil {
+				onResult(r)
+			}
+		case err, ok := <-errors:
+			if !ok {
+				errors = nil
+				continue
+			}
+			if onError != nil {
+				onError(err)
+			}
+		}
+	}
+	return <-runErr
+}
+
+// runWordlist 对指定的目标完整地遍历一次字典,base为空时使用插件默认的目标
+func (g *Gobuster) runWordlist(ctx context.Context, base string) error {
 	//开启worker,进行消费
 	var workerGroup sync.WaitGroup
 	workerGroup.Add(g.Opts.Threads)
 
-	wordChan := make(chan string, g.Opts.Threads)
+	wordChan := make(chan wordEntry, g.Opts.Threads)
 	for i := 0; i < g.Opts.Threads; i++ {
-		go g.worker(ctx, wordChan, &workerGroup)
+		go g.worker(ctx, base, wordChan, &workerGroup)
 	}
 
 	//开始生产任务
-	scanner, err := g.getWordList()
+	scanner, closeWordlist, err := g.getWordList()
 	if err != nil {
+		close(wordChan)
+		workerGroup.Wait()
 		return err
 	}
+	defer closeWordlist()
+
+	//从stdin读取时无法重新读取,需要递归时缓存下来供后续的目标使用
+	rp, ok := g.plugin.(RecursivePlugin)
+	cacheWords := ok && rp.Recursive() && g.Opts.Words == nil && g.Opts.Wordlist == "-" && g.stdinWords == nil
+
+	//恢复扫描时跳过已经处理完毕的行,只对最初的目标生效
+	skip := 0
+	if base == "" {
+		skip = g.ResumeOffset
+		g.offset.reset(skip)
+	}
+	index := 0
 SCAN:
 	for scanner.Scan() {
+		word := scanner.Text()
+		if cacheWords {
+			g.stdinWords = append(g.stdinWords, word)
+		}
+		if index < skip {
+			index++
+			g.incrementResumedBy(len(g.processPatterns(word)))
+			continue
+		}
 		select {
 		case <-ctx.Done():
 			break SCAN
-		default:
-			word := scanner.Text()
-			wordChan <- word
-
+		case wordChan <- wordEntry{index: index, word: word}:
 		}
+		index++
 	}
 
 	//生产完毕关闭wordChan,能够使所有Worker感知到
@@ -94,69 +240,173 @@ SCAN:
 	return nil
 }
 
-func (g *Gobuster) worker(ctx context.Context, wordChan <-chan string, wg *sync.WaitGroup) {
+func (g *Gobuster) worker(ctx context.Context, base string, wordChan <-chan wordEntry, wg *sync.WaitGroup) {
 	defer wg.Done()
 	for {
 		select {
 		case <-ctx.Done():
 			return
 
-		case word, ok := <-wordChan:
+		case entry, ok := <-wordChan:
 			if !ok {
 				return
 			}
-			g.incrementRequest()
-
-			wordCleaned := strings.TrimSpace(word)
-			//舍弃无效的
+			wordCleaned := strings.TrimSpace(entry.word)
+			words := g.processPatterns(wordCleaned)
+			//舍弃无效的(仍计入请求数,保证与RequestExpected一致)
 			if strings.HasPrefix(wordCleaned, "#") || len(wordCleaned) == 0 {
+				g.incrementRequestBy(len(words))
+				g.markDone(base, entry.index)
 				break
 			}
 
-			//调用接口进行执行(结果将被放入chan Result)
-			err := g.plugin.Run(ctx, wordCleaned, g.resultChan)
-			if err != nil {
-				//出现错误不退出
-				g.errorChan <- err
-			}
+			//依次执行原始单词及其经过pattern替换后的单词
+			for _, w := range words {
+				if ctx.Err() != nil {
+					return
+				}
+				g.incrementRequest()
+
+				//调用接口进行执行(结果将被放入chan Result)
+				err := g.runWord(ctx, base, w)
+				if err != nil {
+					//出现错误不退出
+					g.incrementError()
+					g.addFailedWords(err, wordCleaned)
+					g.errorChan <- err
+				}
 
-			//一定延迟后继续
-			select {
-			case <-ctx.Done():
-			case <-time.After(g.Opts.Delay):
+				//一定延迟后继续
+				select {
+				case <-ctx.Done():
+				case <-time.After(g.Opts.Delay):
+				}
+			}
+			//被取消时单词可能没有执行完整,不能计入已处理
+			if ctx.Err() != nil {
+				return
 			}
+			g.markDone(base, entry.index)
+		}
+	}
+}
+
+// markDone 记录最初的目标中已经处理完毕的行
+func (g *Gobuster) markDone(base string, index int) {
+	if base == "" {
+		g.offset.done(index)
+	}
+}
+
+// Processed 返回字典中从头开始连续处理完毕的行数,恢复扫描时从该位置继续
+func (g *Gobuster) Processed() int {
+	return g.offset.get()
+}
+
+// runWord 对单个单词执行插件,指定了base时交由RecursivePlugin处理
+func (g *Gobuster) runWord(ctx context.Context, base, word string) error {
+	if base == "" {
+		return g.plugin.Run(ctx, word, g.resultChan)
+	}
+	return g.plugin.(RecursivePlugin).RunBase(ctx, base, word, g.resultChan)
+}
 
+func (g *Gobuster) incrementError() {
+	g.RequestCountMutex.Lock()
+	defer g.RequestCountMutex.Unlock()
+	g.ErrorCount++
+}
+
+// addFailedWord 记录执行失败的单词(记录原始单词,重新执行时会再次进行pattern替换)
+func (g *Gobuster) addFailedWord(word string) {
+	g.failedMutex.Lock()
+	defer g.failedMutex.Unlock()
+	g.failedWords.Add(word)
+}
+
+// addFailedWords 记录错误对应的单词,插件返回ErrWords时记录其中所有的单词,否则记录word
+func (g *Gobuster) addFailedWords(err error, word string) {
+	var we *ErrWords
+	if errors.As(err, &we) {
+		for _, w := range we.Words {
+			g.addFailedWord(w)
 		}
+		return
+	}
+	if word != "" {
+		g.addFailedWord(word)
+	}
+}
+
+// FailedWords 返回执行失败的单词,可以作为字典重新执行
+func (g *Gobuster) FailedWords() []string {
+	g.failedMutex.Lock()
+	defer g.failedMutex.Unlock()
+	words := make([]string, 0, g.failedWords.Length())
+	for w := range g.failedWords.Set {
+		words = append(words, w)
+	}
+	sort.Strings(words)
+	return words
+}
+
+// processPatterns 返回原始单词以及使用其替换每个pattern中{GOBUSTER}占位符后的结果
+func (g *Gobuster) processPatterns(word string) []string {
+	words := []string{word}
+	if len(g.Opts.Patterns) == 0 {
+		return words
+	}
+	for _, p := range g.Opts.Patterns {
+		words = append(words, strings.ReplaceAll(p, "{GOBUSTER}", word))
 	}
+	return words
 }
 
-func (g *Gobuster) getWordList() (*bufio.Scanner, error) {
+// getWordList 打开字典并累加预期的请求数,返回的函数用于关闭字典文件
+func (g *Gobuster) getWordList() (*bufio.Scanner, func(), error) {
+	if g.Opts.Words != nil {
+		g.addExpected(len(g.Opts.Words))
+		return bufio.NewScanner(strings.NewReader(strings.Join(g.Opts.Words, "\n"))), func() {}, nil
+	}
 	if g.Opts.Wordlist == "-" {
+		//已经缓存了stdin的内容(递归扫描的后续目标)
+		if g.stdinWords != nil {
+			g.addExpected(len(g.stdinWords))
+			return bufio.NewScanner(strings.NewReader(strings.Join(g.stdinWords, "\n"))), func() {}, nil
+		}
 		// Read directly from stdin
-		return bufio.NewScanner(os.Stdin), nil
+		return bufio.NewScanner(os.Stdin), func() {}, nil
 	}
 	// Pull content from the wordlist
 	wordlist, err := os.Open(g.Opts.Wordlist)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open wordlist: %w", err)
+		return nil, nil, fmt.Errorf("failed to open wordlist: %w", err)
 	}
 	//统计文件行数,便于进度条实现
 	lines, err := lineCounter(wordlist)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get number of lines: %w", err)
+		wordlist.Close()
+		return nil, nil, fmt.Errorf("failed to get number of lines: %w", err)
 	}
-	g.RequestIssued = 0
-
-	g.RequestExpected = lines
-	if g.Opts.PatternFile != "" {
-		g.RequestExpected += lines * len(g.Opts.Patterns)
-	}
-	g.RequestExpected *= g.plugin.RequestPerRun()
+	g.addExpected(lines)
 
 	//重置wordlist
 	_, err = wordlist.Seek(0, 0)
 	if err != nil {
-		return nil, fmt.Errorf("failed to rewind wordlist: %w", err)
+		wordlist.Close()
+		return nil, nil, fmt.Errorf("failed to rewind wordlist: %w", err)
 	}
-	return bufio.NewScanner(wordlist), nil
+	return bufio.NewScanner(wordlist), func() { wordlist.Close() }, nil
+}
+
+// addExpected 根据字典行数累加预期的请求数(递归扫描时每个新目标都会增加一次)
+func (g *Gobuster) addExpected(lines int) {
+	expected := lines
+	expected += lines * len(g.Opts.Patterns)
+	expected *= g.plugin.RequestPerRun()
+
+	//worker已经启动,进度条也会并发读取,需要加锁
+	g.RequestCountMutex.Lock()
+	g.RequestExpected += expected
+	g.RequestCountMutex.Unlock()
 }
@@ -0,0 +1,208 @@
+package lib
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Range 闭区间,Max小于0表示没有上限
+type Range struct {
+	Min, Max int64
+}
+
+// RangeSet 多个区间的集合,如100-200,300,400-
+type RangeSet struct {
+	Ranges []Range
+}
+
+// Contains 判断v是否落在任意一个区间内
+func (set *RangeSet) Contains(v int64) bool {
+	for _, r := range set.Ranges {
+		if v >= r.Min && (r.Max < 0 || v <= r.Max) {
+			return true
+		}
+	}
+	return false
+}
+
+func (set *RangeSet) Length() int {
+	return len(set.Ranges)
+}
+
+func (set *RangeSet) Stringify() string {
+	values := make([]string, len(set.Ranges))
+	for i, r := range set.Ranges {
+		switch {
+		case r.Max < 0:
+			values[i] = fmt.Sprintf("%d-", r.Min)
+		case r.Min == r.Max:
+			values[i] = strconv.FormatInt(r.Min, 10)
+		default:
+			values[i] = fmt.Sprintf("%d-%d", r.Min, r.Max)
+		}
+	}
+	return strings.Join(values, ",")
+}
+
+// MatcherOptions 根据响应内容判断结果的配置,match要求全部满足,filter满足任意一个即排除
+type MatcherOptions struct {
+	MatchRegex  string
+	FilterRegex string
+	MatchSize   RangeSet
+	FilterSize  RangeSet
+	MatchWords  RangeSet
+	FilterWords RangeSet
+	MatchLines  RangeSet
+	FilterLines RangeSet
+}
+
+// MatchResponse 用于匹配的响应信息
+type MatchResponse struct {
+	StatusCode int
+	Size       int64
+	Header     http.Header
+	Body       []byte
+}
+
+// Words 以空白分隔的单词数
+func (r *MatchResponse) Words() int64 {
+	return int64(len(bytes.Fields(r.Body)))
+}
+
+// Lines 行数,最后一行没有换行符时同样计入
+func (r *MatchResponse) Lines() int64 {
+	if len(r.Body) == 0 {
+		return 0
+	}
+	lines := int64(bytes.Count(r.Body, []byte{'\n'}))
+	if r.Body[len(r.Body)-1] != '\n' {
+		lines++
+	}
+	return lines
+}
+
+// matcher 对响应的单个判断条件
+type matcher func(r *MatchResponse) bool
+
+// ResponseMatcher 由多个match和filter条件组成,供各个插件复用
+type ResponseMatcher struct {
+	matchers  []matcher
+	filters   []matcher
+	needsBody bool
+}
+
+// NewResponseMatcher 根据配置生成ResponseMatcher,没有任何条件时Allow总是返回true
+func NewResponseMatcher(opts MatcherOptions) (*ResponseMatcher, error) {
+	m := &ResponseMatcher{}
+
+	if opts.MatchRegex != "" {
+		re, err := regexp.Compile(opts.MatchRegex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid match regex %q: %w", opts.MatchRegex, err)
+		}
+		m.matchers = append(m.matchers, regexMatcher(re))
+		m.needsBody = true
+	}
+	if opts.FilterRegex != "" {
+		re, err := regexp.Compile(opts.FilterRegex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter regex %q: %w", opts.FilterRegex, err)
+		}
+		m.filters = append(m.filters, regexMatcher(re))
+		m.needsBody = true
+	}
+
+	if opts.MatchSize.Length() > 0 {
+		m.matchers = append(m.matchers, sizeMatcher(opts.MatchSize))
+	}
+	if opts.FilterSize.Length() > 0 {
+		m.filters = append(m.filters, sizeMatcher(opts.FilterSize))
+	}
+
+	if opts.MatchWords.Length() > 0 {
+		m.matchers = append(m.matchers, wordsMatcher(opts.MatchWords))
+		m.needsBody = true
+	}
+	if opts.FilterWords.Length() > 0 {
+		m.filters = append(m.filters, wordsMatcher(opts.FilterWords))
+		m.needsBody = true
+	}
+
+	if opts.MatchLines.Length() > 0 {
+		m.matchers = append(m.matchers, linesMatcher(opts.MatchLines))
+		m.needsBody = true
+	}
+	if opts.FilterLines.Length() > 0 {
+		m.filters = append(m.filters, linesMatcher(opts.FilterLines))
+		m.needsBody = true
+	}
+
+	return m, nil
+}
+
+// NeedsBody 是否有条件需要读取响应的body
+func (m *ResponseMatcher) NeedsBody() bool {
+	return m.needsBody
+}
+
+// Allow 满足所有match条件,且不满足任何filter条件时返回true
+func (m *ResponseMatcher) Allow(r *MatchResponse) bool {
+	for _, f := range m.filters {
+		if f(r) {
+			return false
+		}
+	}
+	for _, match := range m.matchers {
+		if !match(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func regexMatcher(re *regexp.Regexp) matcher {
+	return func(r *MatchResponse) bool {
+		return re.Match(r.Body)
+	}
+}
+
+func sizeMatcher(set RangeSet) matcher {
+	return func(r *MatchResponse) bool {
+		return set.Contains(r.Size)
+	}
+}
+
+func wordsMatcher(set RangeSet) matcher {
+	return func(r *MatchResponse) bool {
+		return set.Contains(r.Words())
+	}
+}
+
+func linesMatcher(set RangeSet) matcher {
+	return func(r *MatchResponse) bool {
+		return set.Contains(r.Lines())
+	}
+}
+
+// Stringify 输出已配置的条件,用于GetConfigString
+func (opts *MatcherOptions) Stringify() string {
+	var parts []string
+	add := func(name, value string) {
+		if value != "" {
+			parts = append(parts, fmt.Sprintf("%s=%s", name, value))
+		}
+	}
+	add("match-regex", opts.MatchRegex)
+	add("filter-regex", opts.FilterRegex)
+	add("match-size", opts.MatchSize.Stringify())
+	add("filter-size", opts.FilterSize.Stringify())
+	add("match-words", opts.MatchWords.Stringify())
+	add("filter-words", opts.FilterWords.Stringify())
+	add("match-lines", opts.MatchLines.Stringify())
+	add("filter-lines", opts.FilterLines.Stringify())
+	return strings.Join(parts, " ")
+}
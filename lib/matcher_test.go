@@ -0,0 +1,91 @@
+package lib
+
+import "testing"
+
+func TestRangeSetContains(t *testing.T) {
+	//100-200,300,400-
+	set := RangeSet{Ranges: []Range{{100, 200}, {300, 300}, {400, -1}}}
+	tests := []struct {
+		v    int64
+		want bool
+	}{
+		{99, false},
+		{100, true},
+		{200, true},
+		{201, false},
+		{300, true},
+		{399, false},
+		{400, true},
+		{1 << 40, true},
+	}
+	for _, tt := range tests {
+		if got := set.Contains(tt.v); got != tt.want {
+			t.Errorf("%d: got %v, want %v", tt.v, got, tt.want)
+		}
+	}
+	if s := set.Stringify(); s != "100-200,300,400-" {
+		t.Errorf("got %q, want 100-200,300,400-", s)
+	}
+}
+
+func TestLinesAndWords(t *testing.T) {
+	tests := []struct {
+		body         string
+		lines, words int64
+	}{
+		{"", 0, 0},
+		{"one", 1, 1},
+		{"one\n", 1, 1},
+		{"one\ntwo", 2, 2},
+		{"one two\nthree\n\n", 3, 3},
+		{"\n", 1, 0},
+	}
+	for _, tt := range tests {
+		r := &MatchResponse{Body: []byte(tt.body)}
+		if got := r.Lines(); got != tt.lines {
+			t.Errorf("%q: got %d lines, want %d", tt.body, got, tt.lines)
+		}
+		if got := r.Words(); got != tt.words {
+			t.Errorf("%q: got %d words, want %d", tt.body, got, tt.words)
+		}
+	}
+}
+
+func TestResponseMatcherAllow(t *testing.T) {
+	tests := []struct {
+		name string
+		opts MatcherOptions
+		body string
+		want bool
+	}{
+		{"no conditions", MatcherOptions{}, "anything", true},
+		{"match regex", MatcherOptions{MatchRegex: "admin"}, "admin panel", true},
+		{"match regex miss", MatcherOptions{MatchRegex: "admin"}, "login", false},
+		{"filter wins over match", MatcherOptions{MatchRegex: "admin", FilterRegex: "denied"}, "admin denied", false},
+		{"filter lines wins over match size", MatcherOptions{
+			MatchSize:   RangeSet{Ranges: []Range{{0, -1}}},
+			FilterLines: RangeSet{Ranges: []Range{{2, 2}}},
+		}, "a\nb", false},
+		{"all matchers must match", MatcherOptions{
+			MatchWords: RangeSet{Ranges: []Range{{2, -1}}},
+			MatchLines: RangeSet{Ranges: []Range{{2, -1}}},
+		}, "a b", false},
+		{"open range", MatcherOptions{MatchSize: RangeSet{Ranges: []Range{{3, -1}}}}, "abcdef", true},
+	}
+	for _, tt := range tests {
+		m, err := NewResponseMatcher(tt.opts)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		r := &MatchResponse{StatusCode: 200, Size: int64(len(tt.body)), Body: []byte(tt.body)}
+		if got := m.Allow(r); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestResponseMatcherInvalidRegex(t *testing.T) {
+	if _, err := NewResponseMatcher(MatcherOptions{FilterRegex: "("}); err == nil {
+		t.Fatal("got nil, want an error for an invalid regex")
+	}
+}
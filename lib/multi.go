@@ -0,0 +1,202 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// TargetResult 多目标扫描时的结果,标记了结果所属的目标
+type TargetResult struct {
+	Target string
+	Result Result
+}
+
+// Fields 实现Result接口,插件没有设置目标时使用TargetResult中的目标
+func (r TargetResult) Fields() ResultFields {
+	f := r.Result.Fields()
+	if f.Target == "" {
+		f.Target = r.Target
+	}
+	return f
+}
+
+// target 多目标扫描中的一个目标
+type target struct {
+	name   string
+	plugin GobusterPlugin
+	sem    chan struct{} //限制对同一个host的并发数,同一个host的目标共享
+}
+
+// MultiTargetPlugin 将同一个字典同时用于多个目标,每个目标对应一个插件实例
+type MultiTargetPlugin struct {
+	targets       []*target
+	preRunErrors  []error
+	requestPerRun int
+	extras        []ExtraRequestsPlugin //会按需发起额外请求的插件,创建后不再修改,进度可以并发读取
+}
+
+// NewMultiTargetPlugin 根据目标及其对应的插件生成MultiTargetPlugin,perHost为每个host的最大并发数
+func NewMultiTargetPlugin(names []string, plugins []GobusterPlugin, perHost int) (*MultiTargetPlugin, error) {
+	if len(names) == 0 || len(names) != len(plugins) {
+		return nil, fmt.Errorf("please provide one plugin for every target")
+	}
+	if perHost <= 0 {
+		return nil, fmt.Errorf("the concurrency per host must be bigger than 0")
+	}
+	m := MultiTargetPlugin{}
+	sems := make(map[string]chan struct{})
+	for i, name := range names {
+		//目标不是url时(如域名)以目标本身作为host
+		host := name
+		if u, err := url.Parse(name); err == nil && u.Host != "" {
+			host = u.Host
+		}
+		if _, ok := sems[host]; !ok {
+			sems[host] = make(chan struct{}, perHost)
+		}
+		m.targets = append(m.targets, &target{
+			name:   name,
+			plugin: plugins[i],
+			sem:    sems[host],
+		})
+		if ep, ok := plugins[i].(ExtraRequestsPlugin); ok {
+			m.extras = append(m.extras, ep)
+		}
+	}
+	return &m, nil
+}
+
+func (m *MultiTargetPlugin) Name() string {
+	return fmt.Sprintf("%s (%d targets)", m.targets[0].plugin.Name(), len(m.targets))
+}
+
+// RequestPerRun 每个单词会对所有可用的目标执行一次
+func (m *MultiTargetPlugin) RequestPerRun() int {
+	if m.requestPerRun > 0 {
+		return m.requestPerRun
+	}
+	num := 0
+	for _, t := range m.targets {
+		num += t.plugin.RequestPerRun()
+	}
+	return num
+}
+
+// ExtraRequests 汇总所有目标按需发起的额外请求
+func (m *MultiTargetPlugin) ExtraRequests() int {
+	num := 0
+	for _, ep := range m.extras {
+		num += ep.ExtraRequests()
+	}
+	return num
+}
+
+// PreRun 并发执行所有目标的PreRun,失败的目标被跳过,只有全部失败时才返回错误
+func (m *MultiTargetPlugin) PreRun(ctx context.Context) error {
+	errs := make([]error, len(m.targets))
+	var wg sync.WaitGroup
+	for i, t := range m.targets {
+		wg.Add(1)
+		go func(i int, t *target) {
+			defer wg.Done()
+			errs[i] = t.plugin.PreRun(ctx)
+		}(i, t)
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	var active []*target
+	for i, t := range m.targets {
+		if errs[i] != nil {
+			m.preRunErrors = append(m.preRunErrors, fmt.Errorf("skipping target %s: %w", t.name, errs[i]))
+			continue
+		}
+		active = append(active, t)
+	}
+	if len(active) == 0 {
+		return fmt.Errorf("all targets failed: %w", combineErrors(m.preRunErrors))
+	}
+	m.targets = active
+	m.requestPerRun = 0
+	m.requestPerRun = m.RequestPerRun()
+	return nil
+}
+
+// PreRunErrors 返回PreRun中被跳过的目标及其原因
+func (m *MultiTargetPlugin) PreRunErrors() []error {
+	return m.preRunErrors
+}
+
+// Run 对所有目标并发执行同一个单词,结果中标记所属的目标
+func (m *MultiTargetPlugin) Run(ctx context.Context, word string, results chan<- Result) error {
+	errs := make([]error, len(m.targets))
+	var wg sync.WaitGroup
+	for i, t := range m.targets {
+		wg.Add(1)
+		go func(i int, t *target) {
+			defer wg.Done()
+			select {
+			case <-ctx.Done():
+				return
+			case t.sem <- struct{}{}:
+			}
+			defer func() { <-t.sem }()
+
+			//转发结果,并加上目标的标记
+			inner := make(chan Result)
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for r := range inner {
+					results <- TargetResult{Target: t.name, Result: r}
+				}
+			}()
+			if err := t.plugin.Run(ctx, word, inner); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", t.name, err)
+			}
+			close(inner)
+			<-done
+		}(i, t)
+	}
+	wg.Wait()
+
+	var failed []error
+	for _, err := range errs {
+		if err != nil {
+			failed = append(failed, err)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return combineErrors(failed)
+}
+
+func (m *MultiTargetPlugin) GetConfigString() (string, error) {
+	s, err := m.targets[0].plugin.GetConfigString()
+	if err != nil {
+		return "", err
+	}
+	names := make([]string, len(m.targets))
+	for i, t := range m.targets {
+		names[i] = t.name
+	}
+	return fmt.Sprintf("%s\n[+] Targets:\t%s", s, strings.Join(names, ",")), nil
+}
+
+// combineErrors 将多个错误合并为一个,保留第一个错误以便errors.Is/As判断
+func combineErrors(errs []error) error {
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return fmt.Errorf("%w (and %d more: %s)", errs[0], len(errs)-1, strings.Join(msgs[1:], "; "))
+}
@@ -0,0 +1,37 @@
+package lib
+
+import "sync"
+
+// wordOffset 记录字典的处理进度,worker并发执行导致完成的顺序不确定,
+// 因此只记录从头开始连续完成的行数,其后已完成的行暂存在pending中
+type wordOffset struct {
+	mu      sync.Mutex
+	next    int
+	pending map[int]bool
+}
+
+func (o *wordOffset) reset(start int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.next = start
+	o.pending = make(map[int]bool)
+}
+
+func (o *wordOffset) done(index int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.pending == nil {
+		o.pending = make(map[int]bool)
+	}
+	o.pending[index] = true
+	for o.pending[o.next] {
+		delete(o.pending, o.next)
+		o.next++
+	}
+}
+
+func (o *wordOffset) get() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.next
+}
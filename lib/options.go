@@ -6,15 +6,25 @@ import "time"
 type Options struct {
 	Threads        int
 	Wordlist       string
+	Words          []string //直接提供的字典(作为库使用时),设置后不再读取Wordlist
 	PatternFile    string
 	Patterns       []string
 	OutputFilename string
-	NoStatus       bool
+	OutputFormat   string   //text,jsonl或json
+	OutputHeaders  []string //json格式中需要输出的响应头
+	FailedFilename string   //写入执行失败的单词,可以作为字典重新执行
+	NoStatus       bool     //文本输出中不显示状态码
+	Expanded       bool     //文本输出中显示完整的url
+	HideLength     bool     //文本输出中不显示响应的大小
 	NoProgress     bool
 	NoError        bool
 	Quiet          bool
 	Verbose        bool
 	Delay          time.Duration
+	StateFile      string              //定期保存扫描进度的文件
+	Resume         bool                //是否从StateFile中恢复扫描
+	StateCommand   string              //保存在StateFile中的子命令
+	StateFlags     map[string][]string //保存在StateFile中的命令行参数
 }
 
 func NewOptions() *Options {
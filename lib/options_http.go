@@ -1,13 +1,37 @@
 package lib
 
-import "time"
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
 
 // BasicHTTPOptions defines only core http options
 type BasicHTTPOptions struct {
 	UserAgent       string
 	Proxy           string
+	Proxies         []string //多个代理,与Proxy一起轮流使用
 	NoTLSValidation bool
+	ClientCertFile  string //客户端证书(PEM或PKCS#12)
+	ClientKeyFile   string //客户端证书的私钥(PEM),证书中已包含私钥时为空
+	ClientCertPass  string //PKCS#12证书的密码
+	CACertFile      string //自定义的CA证书(PEM)
+	TLSMinVersion   string //最低TLS版本,如1.2
+	SNI             string //TLS握手时使用的ServerName
 	Timeout         time.Duration
+	RateLimit       int           //所有worker每秒最多发起的请求数,0表示不限制
+	RateBurst       int           //限速时允许瞬间发起的请求数
+	Limiter         *RateLimiter  //多个client共享的限速器,为nil时根据RateLimit创建
+	RetryAttempts   int           //临时性错误的最大重试次数
+	RetryBackoff    time.Duration //第一次重试前的等待时间,之后每次翻倍
+	RetryJitter     bool          //等待时间是否加入随机抖动
+	RetryOnStatus   bool          //是否对429/503响应进行重试
+	ForceHTTP1      bool          //只使用HTTP/1.1
+	HTTP2           bool          //使用HTTP/2,明文请求使用h2c
+	MaxConnsPerHost int           //每个host的最大连接数,0表示不限制
+	IdleConnTimeout time.Duration //空闲连接的超时时间
+	NoKeepAlive     bool          //每个请求使用新的连接
 }
 
 // HTTPOptions is the struct to pass in all http options to Gobuster
@@ -21,3 +45,41 @@ type HTTPOptions struct {
 	FollowRedirect bool
 	Method         string
 }
+
+// WriteHTTPConfig 将设置过的http配置(限速、重试、代理、TLS以及User-Agent)写入GetConfigString使用的tabwriter
+func (opt *BasicHTTPOptions) WriteHTTPConfig(w io.Writer) error {
+	var lines []string
+	if opt.RateLimit > 0 {
+		lines = append(lines, fmt.Sprintf("Rate limit:\t%d req/s (burst %d)", opt.RateLimit, opt.RateBurst))
+	}
+	if opt.RetryAttempts > 0 {
+		lines = append(lines, fmt.Sprintf("Retries:\t%d (backoff %s)", opt.RetryAttempts, opt.RetryBackoff))
+	}
+	if opt.Proxy != "" {
+		lines = append(lines, fmt.Sprintf("Proxy:\t%s", opt.Proxy))
+	}
+	if len(opt.Proxies) > 0 {
+		lines = append(lines, fmt.Sprintf("Proxy list:\t%s", strings.Join(opt.Proxies, ", ")))
+	}
+	if opt.ClientCertFile != "" {
+		lines = append(lines, fmt.Sprintf("Client Certificate:\t%s", opt.ClientCertFile))
+	}
+	if opt.CACertFile != "" {
+		lines = append(lines, fmt.Sprintf("CA Certificate:\t%s", opt.CACertFile))
+	}
+	if opt.TLSMinVersion != "" {
+		lines = append(lines, fmt.Sprintf("Min TLS Version:\t%s", opt.TLSMinVersion))
+	}
+	if opt.SNI != "" {
+		lines = append(lines, fmt.Sprintf("SNI:\t%s", opt.SNI))
+	}
+	if opt.UserAgent != "" {
+		lines = append(lines, fmt.Sprintf("User Agent:\t%s", opt.UserAgent))
+	}
+	for _, l := range lines {
+		if _, err := fmt.Fprintf(w, "[+] %s\n", l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
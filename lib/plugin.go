@@ -1,6 +1,9 @@
 package lib
 
-import "context"
+import (
+	"context"
+	"fmt"
+)
 
 // GobusterPlugin 可执行目录爆破的接口
 type GobusterPlugin interface {
@@ -16,6 +19,56 @@ type GobusterPlugin interface {
 	GetConfigString() (string, error)
 }
 
+// RecursivePlugin 可选接口,插件在扫描过程中发现新的目标(如目录)时,引擎会对新目标重新执行整个字典
+type RecursivePlugin interface {
+	GobusterPlugin
+
+	// Recursive 是否开启了递归
+	Recursive() bool
+	// NewBases 返回上次调用之后新发现的目标(由插件负责去重和深度控制)
+	NewBases() []string
+	// PreRunBase 在对新目标开始扫描前执行(如通配检查),返回错误时跳过该目标
+	PreRunBase(ctx context.Context, base string) error
+	// RunBase 与Run相同,但针对指定的目标
+	RunBase(ctx context.Context, base string, word string, results chan<- Result) error
+}
+
+// PreRunErrorsPlugin 可选接口,返回PreRun中出现但不影响继续执行的错误(如多目标中被跳过的目标)
+type PreRunErrorsPlugin interface {
+	PreRunErrors() []error
+}
+
+// PostRunPlugin 可选接口,字典遍历完毕后执行(如处理插件中缓存的剩余单词)
+type PostRunPlugin interface {
+	PostRun(ctx context.Context, results chan<- Result) error
+}
+
+// ProgressUnitPlugin 可选接口,每个单词对应的请求数不固定时,进度按照返回的单位(如words)显示,不再显示为请求数
+type ProgressUnitPlugin interface {
+	ProgressUnit() string
+}
+
+// ExtraRequestsPlugin 可选接口,插件除了RequestPerRun之外还会按需发起请求(如对命中的结果追加探测)时,
+// 返回到目前为止发起的这些请求的数量,计入进度中
+type ExtraRequestsPlugin interface {
+	ExtraRequests() int
+}
+
+// ErrWords Run或PostRun一次处理了多个单词(如params中的一批参数)并且失败时返回,引擎将其中所有的单词记录为失败
+type ErrWords struct {
+	Words []string
+	Err   error
+}
+
+func (e *ErrWords) Error() string {
+	return fmt.Sprintf("%s (%d words)", e.Err, len(e.Words))
+}
+
+func (e *ErrWords) Unwrap() error {
+	return e.Err
+}
+
+// Result 插件产生的结果,只提供结构化的数据,输出的格式由使用者决定
 type Result interface {
-	ResulToString() (string, error)
+	Fields() ResultFields
 }
@@ -0,0 +1,63 @@
+package lib
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter 令牌桶限速,由使用它的所有HTTPClient的worker共享
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 //每秒产生的令牌数
+	burst  float64 //桶的容量
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter 创建限速器,rate为每秒的请求数,burst为允许瞬间发起的请求数
+func NewRateLimiter(rate, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:   float64(rate),
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait 获取一个令牌,没有令牌时阻塞直到令牌可用或ctx被取消
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	//先预定令牌,令牌数为负时表示需要等待的时间
+	l.tokens--
+	var wait time.Duration
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		//归还预定的令牌
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,26 @@
+package lib
+
+import (
+	"net/http"
+	"time"
+)
+
+// ResultFields 结果的结构化数据,由插件填充,如何输出由cli中的formatter决定
+type ResultFields struct {
+	Plugin     string //产生结果的插件(如dir、dns),用于选择文本格式
+	Target     string //扫描的目标(url或域名)
+	Found      bool
+	URL        string
+	Path       string
+	Host       string
+	Word       string
+	StatusCode int
+	Size       int64
+	Header     http.Header
+	Duration   time.Duration //发起请求所用的时间
+	IPs        []string
+	CNAME      string
+	Info       string   //结果的补充说明,如存储桶的访问权限
+	Items      []string //结果附带的条目,如存储桶中列出的文件
+	Methods    []string //探测到允许的method及其状态码,或只在Allow头中列出(advertised)
+}
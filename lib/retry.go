@@ -0,0 +1,117 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	maxRetryBackoff     = 30 * time.Second
+	defaultRetryBackoff = 100 * time.Millisecond
+)
+
+// retryPolicy 请求失败时的重试策略
+type retryPolicy struct {
+	Attempts int
+	Backoff  time.Duration
+	Jitter   bool
+	OnStatus bool
+}
+
+// delay 判断第attempt次请求的结果是否需要重试,需要时返回重试前的等待时间
+func (p retryPolicy) delay(attempt int, statusCode *int, header http.Header, err error) (time.Duration, bool) {
+	if attempt >= p.Attempts {
+		return 0, false
+	}
+
+	switch {
+	case err != nil:
+		if !isTransientError(err) {
+			return 0, false
+		}
+	case p.retryStatus(statusCode):
+		//服务端指定了等待时间时优先使用
+		if d, ok := parseRetryAfter(header.Get("Retry-After")); ok {
+			return d, true
+		}
+	default:
+		return 0, false
+	}
+
+	return p.backoff(attempt), true
+}
+
+// retryStatus 开启了按状态码重试时,429和503需要重试
+func (p retryPolicy) retryStatus(statusCode *int) bool {
+	return statusCode != nil && p.OnStatus &&
+		(*statusCode == http.StatusTooManyRequests || *statusCode == http.StatusServiceUnavailable)
+}
+
+// exhausted 重试次数用完后仍然返回需要重试的状态码时返回错误,使单词被记录为失败
+func (p retryPolicy) exhausted(statusCode *int) error {
+	if p.Attempts <= 0 || !p.retryStatus(statusCode) {
+		return nil
+	}
+	return fmt.Errorf("server still returned status %d after %d retries", *statusCode, p.Attempts)
+}
+
+// backoff 指数退避,抖动时在[d/2,d)之间随机
+func (p retryPolicy) backoff(attempt int) time.Duration {
+	d := p.Backoff
+	if d <= 0 {
+		d = defaultRetryBackoff
+	}
+	for i := 0; i < attempt && d < maxRetryBackoff; i++ {
+		d *= 2
+	}
+	if d > maxRetryBackoff {
+		d = maxRetryBackoff
+	}
+	if p.Jitter {
+		half := d / 2
+		d = half + time.Duration(rand.Int63n(int64(half)+1))
+	}
+	return d
+}
+
+// parseRetryAfter 解析Retry-After头,支持秒数和HTTP时间两种格式,最多等待maxRetryBackoff
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	var d time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		d = time.Duration(seconds) * time.Second
+	} else if t, err := http.ParseTime(value); err == nil {
+		d = time.Until(t)
+	} else {
+		return 0, false
+	}
+	if d < 0 {
+		d = 0
+	}
+	if d > maxRetryBackoff {
+		d = maxRetryBackoff
+	}
+	return d, true
+}
+
+// isTransientError 超时和连接被重置等错误可以通过重试解决
+func isTransientError(err error) bool {
+	var ne net.Error
+	if errors.As(err, &ne) && ne.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF)
+}
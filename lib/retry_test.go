@@ -0,0 +1,151 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		backoff time.Duration
+		attempt int
+		want    time.Duration
+	}{
+		{100 * time.Millisecond, 0, 100 * time.Millisecond},
+		{100 * time.Millisecond, 1, 200 * time.Millisecond},
+		{100 * time.Millisecond, 3, 800 * time.Millisecond},
+		{100 * time.Millisecond, 20, maxRetryBackoff},
+		{0, 0, defaultRetryBackoff},
+		{0, 2, 4 * defaultRetryBackoff},
+		{time.Minute, 0, maxRetryBackoff},
+	}
+	for _, tt := range tests {
+		p := retryPolicy{Backoff: tt.backoff}
+		if got := p.backoff(tt.attempt); got != tt.want {
+			t.Errorf("backoff %s attempt %d: got %s, want %s", tt.backoff, tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestBackoffJitter(t *testing.T) {
+	//抖动后在[d/2,d]之间
+	p := retryPolicy{Backoff: 100 * time.Millisecond, Jitter: true}
+	for i := 0; i < 100; i++ {
+		if got := p.backoff(2); got < 200*time.Millisecond || got > 400*time.Millisecond {
+			t.Fatalf("got %s, want between 200ms and 400ms", got)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"5", 5 * time.Second, true},
+		{"0", 0, true},
+		{"-3", 0, true},
+		{"3600", maxRetryBackoff, true},
+		{"soon", 0, false},
+		{time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0, true},
+		{time.Now().Add(time.Hour).UTC().Format(http.TimeFormat), maxRetryBackoff, true},
+	}
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("%q: got %s %v, want %s %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestDelay(t *testing.T) {
+	tooMany := http.StatusTooManyRequests
+	unavailable := http.StatusServiceUnavailable
+	notFound := http.StatusNotFound
+	retryAfter := http.Header{"Retry-After": []string{"2"}}
+
+	tests := []struct {
+		name       string
+		policy     retryPolicy
+		attempt    int
+		statusCode *int
+		header     http.Header
+		err        error
+		want       time.Duration
+		retry      bool
+	}{
+		{"transient error", retryPolicy{Attempts: 2, Backoff: time.Second}, 1, nil, nil, io.EOF, 2 * time.Second, true},
+		{"permanent error", retryPolicy{Attempts: 2}, 0, nil, nil, errors.New("bad url"), 0, false},
+		{"no attempts left", retryPolicy{Attempts: 2}, 2, nil, nil, io.EOF, 0, false},
+		{"429 without retry on status", retryPolicy{Attempts: 2}, 0, &tooMany, nil, nil, 0, false},
+		{"429 with Retry-After", retryPolicy{Attempts: 2, OnStatus: true}, 0, &tooMany, retryAfter, nil, 2 * time.Second, true},
+		{"503 without Retry-After", retryPolicy{Attempts: 2, Backoff: time.Second, OnStatus: true}, 0, &unavailable, http.Header{}, nil, time.Second, true},
+		{"404", retryPolicy{Attempts: 2, OnStatus: true}, 0, &notFound, http.Header{}, nil, 0, false},
+	}
+	for _, tt := range tests {
+		got, retry := tt.policy.delay(tt.attempt, tt.statusCode, tt.header, tt.err)
+		if got != tt.want || retry != tt.retry {
+			t.Errorf("%s: got %s %v, want %s %v", tt.name, got, retry, tt.want, tt.retry)
+		}
+	}
+}
+
+func TestRequestRetryExhausted(t *testing.T) {
+	for _, status := range []int{http.StatusTooManyRequests, http.StatusServiceUnavailable} {
+		var hits int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&hits, 1)
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(status)
+		}))
+		client, err := NewHTTPClient(&HTTPOptions{BasicHTTPOptions: BasicHTTPOptions{
+			Timeout:       time.Second,
+			RetryAttempts: 2,
+			RetryOnStatus: true,
+		}})
+		if err != nil {
+			t.Fatalf("NewHTTPClient: %v", err)
+		}
+
+		statusCode, _, _, _, err := client.Request(context.Background(), ts.URL, RequestOptions{})
+		ts.Close()
+		if err == nil {
+			t.Errorf("%d: got status %v, want an error after the last retry", status, statusCode)
+		}
+		if n := atomic.LoadInt32(&hits); n != 3 {
+			t.Errorf("%d: got %d requests, want 3", status, n)
+		}
+	}
+}
+
+func TestRequestRetryRecovers(t *testing.T) {
+	var hits int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer ts.Close()
+	client, err := NewHTTPClient(&HTTPOptions{BasicHTTPOptions: BasicHTTPOptions{
+		Timeout:       time.Second,
+		RetryAttempts: 2,
+		RetryOnStatus: true,
+	}})
+	if err != nil {
+		t.Fatalf("NewHTTPClient: %v", err)
+	}
+
+	statusCode, _, _, _, err := client.Request(context.Background(), ts.URL, RequestOptions{})
+	if err != nil || statusCode == nil || *statusCode != http.StatusOK {
+		t.Fatalf("got status %v err %v, want 200", statusCode, err)
+	}
+}
@@ -0,0 +1,106 @@
+package lib
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"software.sslmate.com/src/go-pkcs12"
+	"sort"
+	"strings"
+)
+
+// TLSVersions 支持通过名称指定的最低TLS版本
+var TLSVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// TLSVersionNames 返回所有支持的TLS版本名称,用于提示信息
+func TLSVersionNames() string {
+	names := make([]string, 0, len(TLSVersions))
+	for name := range TLSVersions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// newTLSConfig 根据配置生成tls.Config,包括客户端证书、自定义CA、最低版本以及SNI
+func newTLSConfig(opt *BasicHTTPOptions) (*tls.Config, error) {
+	config := &tls.Config{
+		InsecureSkipVerify: opt.NoTLSValidation,
+		ServerName:         opt.SNI,
+	}
+
+	if opt.TLSMinVersion != "" {
+		v, ok := TLSVersions[opt.TLSMinVersion]
+		if !ok {
+			return nil, fmt.Errorf("invalid tls version %q (must be one of %s)", opt.TLSMinVersion, TLSVersionNames())
+		}
+		config.MinVersion = v
+	}
+
+	if opt.CACertFile != "" {
+		data, err := os.ReadFile(opt.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ca certificate: %w", err)
+		}
+		//在系统CA的基础上加入自定义的CA
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no valid certificates found in %s", opt.CACertFile)
+		}
+		config.RootCAs = pool
+	}
+
+	if opt.ClientCertFile != "" {
+		cert, err := loadClientCertificate(opt.ClientCertFile, opt.ClientKeyFile, opt.ClientCertPass)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	} else if opt.ClientKeyFile != "" {
+		return nil, fmt.Errorf("client key given without a client certificate")
+	}
+	return config, nil
+}
+
+// loadClientCertificate 读取客户端证书,指定了keyFile时按PEM解析,否则按照PEM(证书和私钥在同一个文件中)或PKCS#12解析
+func loadClientCertificate(certFile, keyFile, password string) (tls.Certificate, error) {
+	certData, err := os.ReadFile(certFile)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to read client certificate: %w", err)
+	}
+
+	keyData := certData
+	if keyFile != "" {
+		keyData, err = os.ReadFile(keyFile)
+		if err != nil {
+			return tls.Certificate{}, fmt.Errorf("failed to read client key: %w", err)
+		}
+	} else if !bytes.Contains(certData, []byte("-----BEGIN")) {
+		//不是PEM格式时视为PKCS#12(.p12/.pfx),证书链中的中间证书一起发送
+		key, leaf, chain, err := pkcs12.DecodeChain(certData, password)
+		if err != nil {
+			return tls.Certificate{}, fmt.Errorf("failed to decode pkcs12 client certificate %s: %w", certFile, err)
+		}
+		cert := tls.Certificate{PrivateKey: key, Leaf: leaf, Certificate: [][]byte{leaf.Raw}}
+		for _, c := range chain {
+			cert.Certificate = append(cert.Certificate, c.Raw)
+		}
+		return cert, nil
+	}
+
+	cert, err := tls.X509KeyPair(certData, keyData)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("invalid client certificate %s: %w", certFile, err)
+	}
+	return cert, nil
+}
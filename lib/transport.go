@@ -0,0 +1,205 @@
+package lib
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/proxy"
+	"net"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"time"
+)
+
+// newTransport 根据协议、代理和连接池的配置生成RoundTripper,配置了多个代理时轮流使用
+func newTransport(opt *BasicHTTPOptions, tlsConfig *tls.Config) (http.RoundTripper, error) {
+	if opt.ForceHTTP1 && opt.HTTP2 {
+		return nil, fmt.Errorf("http1 and http2 can not be used together")
+	}
+	if opt.HTTP2 && opt.NoKeepAlive {
+		return nil, fmt.Errorf("keep-alive can not be disabled with http2")
+	}
+	if opt.MaxConnsPerHost < 0 {
+		return nil, fmt.Errorf("max-conns-per-host must be positive")
+	}
+
+	proxies := opt.Proxies
+	if opt.Proxy != "" {
+		proxies = append([]string{opt.Proxy}, proxies...)
+	}
+	if len(proxies) == 0 {
+		return newProxyTransport(opt, nil, tlsConfig)
+	}
+
+	//每个代理使用独立的Transport,连接池互不影响
+	transports := make([]http.RoundTripper, len(proxies))
+	for i, p := range proxies {
+		proxyURL, err := parseProxy(p)
+		if err != nil {
+			return nil, err
+		}
+		transports[i], err = newProxyTransport(opt, proxyURL, tlsConfig.Clone())
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(transports) == 1 {
+		return transports[0], nil
+	}
+	return &roundRobinTransport{transports: transports}, nil
+}
+
+// parseProxy 解析代理地址,支持http/https/socks5/socks5h
+func parseProxy(p string) (*url.URL, error) {
+	proxyURL, err := url.Parse(p)
+	if err != nil {
+		return nil, fmt.Errorf("proxy URL is invalid (%w)", err)
+	}
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("proxy URL %s is invalid (unsupported scheme %q)", p, proxyURL.Scheme)
+	}
+	if proxyURL.Host == "" {
+		return nil, fmt.Errorf("proxy URL %s is invalid (missing host)", p)
+	}
+	return proxyURL, nil
+}
+
+// newProxyTransport 生成经过指定代理的Transport,proxyURL为nil时使用环境变量中的代理
+func newProxyTransport(opt *BasicHTTPOptions, proxyURL *url.URL, tlsConfig *tls.Config) (http.RoundTripper, error) {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	tr := &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		DialContext:         dialer.DialContext,
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 100,
+		MaxConnsPerHost:     opt.MaxConnsPerHost,
+		IdleConnTimeout:     opt.IdleConnTimeout,
+		DisableKeepAlives:   opt.NoKeepAlive,
+		TLSClientConfig:     tlsConfig,
+	}
+	//连接数上限小于空闲连接数时,多余的空闲连接没有意义
+	if opt.MaxConnsPerHost > 0 && opt.MaxConnsPerHost < tr.MaxIdleConnsPerHost {
+		tr.MaxIdleConnsPerHost = opt.MaxConnsPerHost
+	}
+
+	//h2c只能直连或经过socks代理,http代理无法转发
+	h2c := true
+	if proxyURL != nil {
+		switch proxyURL.Scheme {
+		case "socks5", "socks5h":
+			dial, err := newSOCKSDialer(proxyURL, dialer)
+			if err != nil {
+				return nil, err
+			}
+			tr.Proxy = nil
+			tr.DialContext = dial
+		default:
+			tr.Proxy = http.ProxyURL(proxyURL)
+			h2c = false
+		}
+	}
+
+	switch {
+	case opt.ForceHTTP1:
+		//非nil的空map会禁止通过ALPN升级到HTTP/2
+		tr.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+		tlsConfig.NextProtos = []string{"http/1.1"}
+		return tr, nil
+	case opt.HTTP2:
+		//自定义了TLSClientConfig时需要显式开启HTTP/2
+		tr.ForceAttemptHTTP2 = true
+		if err := http2.ConfigureTransport(tr); err != nil {
+			return nil, fmt.Errorf("error on configuring http2: %w", err)
+		}
+		//无法使用h2c时明文请求返回错误,不回退到HTTP/1.1
+		if !h2c {
+			return &h2cTransport{https: tr, proxy: proxyURL}, nil
+		}
+		return &h2cTransport{https: tr, h2c: newH2CTransport(opt, tr.DialContext)}, nil
+	}
+	return tr, nil
+}
+
+// newSOCKSDialer 生成经过socks5代理的DialContext,socks5在本地解析域名,socks5h由代理解析域名
+func newSOCKSDialer(proxyURL *url.URL, forward *net.Dialer) (func(ctx context.Context, network, addr string) (net.Conn, error), error) {
+	var auth *proxy.Auth
+	if proxyURL.User != nil {
+		password, _ := proxyURL.User.Password()
+		auth = &proxy.Auth{User: proxyURL.User.Username(), Password: password}
+	}
+	d, err := proxy.SOCKS5("tcp", proxyURL.Host, auth, forward)
+	if err != nil {
+		return nil, fmt.Errorf("proxy URL %s is invalid (%w)", proxyURL.Redacted(), err)
+	}
+	cd, ok := d.(proxy.ContextDialer)
+	if !ok {
+		return nil, fmt.Errorf("socks5 dialer does not support context")
+	}
+	if proxyURL.Scheme == "socks5h" {
+		return cd.DialContext, nil
+	}
+
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		if net.ParseIP(host) == nil {
+			ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+			if err != nil {
+				return nil, err
+			}
+			if len(ips) == 0 {
+				return nil, fmt.Errorf("no addresses found for %s", host)
+			}
+			addr = net.JoinHostPort(ips[0].IP.String(), port)
+		}
+		return cd.DialContext(ctx, network, addr)
+	}, nil
+}
+
+// newH2CTransport 生成通过明文TCP直接使用HTTP/2(h2c, prior knowledge)的Transport
+func newH2CTransport(opt *BasicHTTPOptions, dial func(ctx context.Context, network, addr string) (net.Conn, error)) *http2.Transport {
+	return &http2.Transport{
+		AllowHTTP:       true,
+		IdleConnTimeout: opt.IdleConnTimeout,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			return dial(ctx, network, addr)
+		},
+	}
+}
+
+// h2cTransport 按照协议选择Transport,https通过ALPN协商HTTP/2,http使用h2c
+type h2cTransport struct {
+	https http.RoundTripper
+	h2c   http.RoundTripper //为nil时经过http代理,明文请求无法使用h2c
+	proxy *url.URL
+}
+
+func (t *h2cTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme == "http" {
+		if t.h2c == nil {
+			if req.Body != nil {
+				req.Body.Close()
+			}
+			return nil, fmt.Errorf("http2 can not be used with http:// targets through http proxy %s (h2c is not supported by http proxies), use https, a socks5 proxy or drop --http2", t.proxy.Redacted())
+		}
+		return t.h2c.RoundTrip(req)
+	}
+	return t.https.RoundTrip(req)
+}
+
+// roundRobinTransport 依次使用每个代理对应的Transport发起请求
+type roundRobinTransport struct {
+	transports []http.RoundTripper
+	next       uint32
+}
+
+func (t *roundRobinTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	i := atomic.AddUint32(&t.next, 1) - 1
+	return t.transports[int(i%uint32(len(t.transports)))].RoundTrip(req)
+}
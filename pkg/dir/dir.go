@@ -0,0 +1,120 @@
+// Package dir 提供可以嵌入其他程序的dir模式扫描,不进行任何输出,也不会退出进程
+//
+//	s, err := dir.New("https://example.com", dir.WithWords("admin", "login"), dir.WithThreads(20))
+//	...
+//	err = s.Run(ctx, func(r dir.Result) { fmt.Println(r.URL, r.StatusCode) })
+package dir
+
+import (
+	internaldir "buster/internal/dir"
+	"buster/lib"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Result 一次发现的结果
+type Result struct {
+	URL        string
+	Path       string
+	StatusCode int
+	Size       int64
+	Header     http.Header
+	Word       string
+	Duration   time.Duration
+	Methods    []string //WithMethodProbe时允许的method及其状态码,或只在Allow头中列出(advertised)
+}
+
+// Scanner 对一个目标执行dir扫描,可以多次执行Run
+type Scanner struct {
+	globalopts *lib.Options
+	opts       *internaldir.OptionsDir
+	onError    func(error)
+}
+
+// New 根据目标和选项生成Scanner,默认值与命令行相同(404视为不存在、10s超时)
+func New(url string, options ...Option) (*Scanner, error) {
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return nil, fmt.Errorf("url scheme not specified for %s", url)
+	}
+
+	s := Scanner{
+		globalopts: lib.NewOptions(),
+		opts:       internaldir.NewOptionsDir(),
+	}
+	s.globalopts.Threads = 10
+	s.globalopts.NoProgress = true
+	s.globalopts.Quiet = true
+
+	s.opts.URL = url
+	s.opts.Method = http.MethodGet
+	s.opts.UserAgent = lib.DefaultUserAgent()
+	s.opts.Timeout = 10 * time.Second
+	s.opts.IdleConnTimeout = 90 * time.Second
+	s.opts.RateBurst = 1
+	s.opts.RetryBackoff = 500 * time.Millisecond
+	s.opts.StatusCodesBlacklist = "404"
+	s.opts.StatusCodesBlacklistParsed.Add(404)
+
+	for _, o := range options {
+		if err := o(&s); err != nil {
+			return nil, err
+		}
+	}
+	if s.globalopts.Words == nil && s.globalopts.Wordlist == "" {
+		return nil, fmt.Errorf("please provide a wordlist or words")
+	}
+	return &s, nil
+}
+
+// Run 执行扫描,每个发现都会调用onResult(在同一个goroutine中依次调用)
+// 只有无法开始扫描(如目标无法连接)时才返回错误,单个请求的错误交给WithErrorHandler
+func (s *Scanner) Run(ctx context.Context, onResult func(Result)) error {
+	plugin, err := internaldir.NewGobusterDir(s.globalopts, s.opts)
+	if err != nil {
+		return err
+	}
+	g, err := lib.NewGobuster(s.globalopts, plugin)
+	if err != nil {
+		return err
+	}
+	return g.RunWithHandlers(ctx, func(r lib.Result) {
+		if onResult == nil {
+			return
+		}
+		f := r.Fields()
+		onResult(Result{
+			URL:        f.URL,
+			Path:       f.Path,
+			Word:       f.Word,
+			StatusCode: f.StatusCode,
+			Size:       f.Size,
+			Header:     f.Header,
+			Duration:   f.Duration,
+			Methods:    f.Methods,
+		})
+	}, s.onError)
+}
+
+// Results 在后台执行扫描,通过chan返回结果,扫描结束后两个chan都会被关闭
+// 错误chan中最多只有一个值,即Run返回的错误
+func (s *Scanner) Results(ctx context.Context) (<-chan Result, <-chan error) {
+	results := make(chan Result)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(errs)
+		defer close(results)
+		err := s.Run(ctx, func(r Result) {
+			select {
+			case results <- r:
+			case <-ctx.Done():
+			}
+		})
+		if err != nil {
+			errs <- err
+		}
+	}()
+	return results, errs
+}
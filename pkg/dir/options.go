@@ -0,0 +1,415 @@
+package dir
+
+import (
+	"buster/helper"
+	"buster/lib"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// Option 用于配置Scanner的函数选项
+type Option func(*Scanner) error
+
+// WithThreads 设置并发数(默认10)
+func WithThreads(threads int) Option {
+	return func(s *Scanner) error {
+		if threads <= 0 {
+			return fmt.Errorf("threads must be bigger than 0")
+		}
+		s.globalopts.Threads = threads
+		return nil
+	}
+}
+
+// WithDelay 设置每个线程两次请求之间的间隔
+func WithDelay(delay time.Duration) Option {
+	return func(s *Scanner) error {
+		if delay < 0 {
+			return fmt.Errorf("delay must be positive")
+		}
+		s.globalopts.Delay = delay
+		return nil
+	}
+}
+
+// WithWordlist 从文件中读取字典
+func WithWordlist(filename string) Option {
+	return func(s *Scanner) error {
+		s.globalopts.Wordlist = filename
+		s.globalopts.Words = nil
+		return nil
+	}
+}
+
+// WithWords 直接使用给定的单词作为字典
+func WithWords(words ...string) Option {
+	return func(s *Scanner) error {
+		s.globalopts.Words = append([]string{}, words...)
+		s.globalopts.Wordlist = ""
+		return nil
+	}
+}
+
+// WithPatterns 对每个单词额外使用pattern进行替换({GOBUSTER}为单词的占位符)
+func WithPatterns(patterns ...string) Option {
+	return func(s *Scanner) error {
+		s.globalopts.Patterns = append([]string{}, patterns...)
+		return nil
+	}
+}
+
+// WithExtensions 设置需要额外尝试的拓展名
+func WithExtensions(extensions ...string) Option {
+	return func(s *Scanner) error {
+		set := lib.NewStringSet()
+		for _, e := range extensions {
+			if e == "" {
+				return fmt.Errorf("invalid empty extension")
+			}
+			set.Add(e)
+		}
+		s.opts.ExtensionsParsed = set
+		s.opts.Extensions = set.Stringify()
+		return nil
+	}
+}
+
+// WithStatusCodes 只将这些状态码视为发现(会清空默认的状态码黑名单)
+func WithStatusCodes(codes ...int) Option {
+	return func(s *Scanner) error {
+		s.opts.StatusCodesParsed = lib.NewIntSet()
+		for _, c := range codes {
+			s.opts.StatusCodesParsed.Add(c)
+		}
+		s.opts.StatusCodes = s.opts.StatusCodesParsed.Stringify()
+		s.opts.StatusCodesBlacklistParsed = lib.NewIntSet()
+		s.opts.StatusCodesBlacklist = ""
+		return nil
+	}
+}
+
+// WithBlacklistStatusCodes 除这些状态码外都视为发现(默认404)
+func WithBlacklistStatusCodes(codes ...int) Option {
+	return func(s *Scanner) error {
+		s.opts.StatusCodesBlacklistParsed = lib.NewIntSet()
+		for _, c := range codes {
+			s.opts.StatusCodesBlacklistParsed.Add(c)
+		}
+		s.opts.StatusCodesBlacklist = s.opts.StatusCodesBlacklistParsed.Stringify()
+		s.opts.StatusCodesParsed = lib.NewIntSet()
+		s.opts.StatusCodes = ""
+		return nil
+	}
+}
+
+// WithExcludeLength 忽略这些长度的响应
+func WithExcludeLength(lengths ...int) Option {
+	return func(s *Scanner) error {
+		s.opts.ExcludeLength = append([]int{}, lengths...)
+		return nil
+	}
+}
+
+// WithMatchRegex 只保留body匹配正则的结果
+func WithMatchRegex(expr string) Option {
+	return func(s *Scanner) error {
+		if _, err := regexp.Compile(expr); err != nil {
+			return fmt.Errorf("invalid match regex: %w", err)
+		}
+		s.opts.Matchers.MatchRegex = expr
+		return nil
+	}
+}
+
+// WithFilterRegex 忽略body匹配正则的结果
+func WithFilterRegex(expr string) Option {
+	return func(s *Scanner) error {
+		if _, err := regexp.Compile(expr); err != nil {
+			return fmt.Errorf("invalid filter regex: %w", err)
+		}
+		s.opts.Matchers.FilterRegex = expr
+		return nil
+	}
+}
+
+// WithMatchSize 只保留大小在区间内的响应,区间的格式与命令行相同(如100-200,300)
+func WithMatchSize(ranges string) Option {
+	return rangeOption(ranges, func(s *Scanner, r lib.RangeSet) { s.opts.Matchers.MatchSize = r })
+}
+
+// WithSizeFilter 使用区间字符串过滤响应大小(如0-10,404)
+func WithSizeFilter(ranges string) Option {
+	return rangeOption(ranges, func(s *Scanner, r lib.RangeSet) { s.opts.Matchers.FilterSize = r })
+}
+
+// WithMatchWords 只保留单词数在区间内的响应
+func WithMatchWords(ranges string) Option {
+	return rangeOption(ranges, func(s *Scanner, r lib.RangeSet) { s.opts.Matchers.MatchWords = r })
+}
+
+// WithFilterWords 忽略单词数在区间内的响应
+func WithFilterWords(ranges string) Option {
+	return rangeOption(ranges, func(s *Scanner, r lib.RangeSet) { s.opts.Matchers.FilterWords = r })
+}
+
+// WithMatchLines 只保留行数在区间内的响应
+func WithMatchLines(ranges string) Option {
+	return rangeOption(ranges, func(s *Scanner, r lib.RangeSet) { s.opts.Matchers.MatchLines = r })
+}
+
+// WithFilterLines 忽略行数在区间内的响应
+func WithFilterLines(ranges string) Option {
+	return rangeOption(ranges, func(s *Scanner, r lib.RangeSet) { s.opts.Matchers.FilterLines = r })
+}
+
+// rangeOption 解析区间字符串后交给set设置
+func rangeOption(ranges string, set func(*Scanner, lib.RangeSet)) Option {
+	return func(s *Scanner) error {
+		r, err := helper.ParseRanges(ranges)
+		if err != nil {
+			return err
+		}
+		set(s, r)
+		return nil
+	}
+}
+
+// WithAddSlash 在每个请求的末尾加上/
+func WithAddSlash() Option {
+	return func(s *Scanner) error {
+		s.opts.UseSlash = true
+		return nil
+	}
+}
+
+// WithDiscoverBackup 发现文件后继续尝试其备份文件
+func WithDiscoverBackup() Option {
+	return func(s *Scanner) error {
+		s.opts.DiscoverBackup = true
+		return nil
+	}
+}
+
+// WithRecursion 递归扫描发现的目录,maxDepth为最大深度
+func WithRecursion(maxDepth int) Option {
+	return func(s *Scanner) error {
+		if maxDepth <= 0 {
+			return fmt.Errorf("max depth must be bigger than 0")
+		}
+		s.opts.Recursive = true
+		s.opts.MaxDepth = maxDepth
+		return nil
+	}
+}
+
+// WithMethod 设置请求使用的方法(默认GET)
+func WithMethod(method string) Option {
+	return func(s *Scanner) error {
+		s.opts.Method = method
+		return nil
+	}
+}
+
+// WithMethodProbe 对每个发现的路径尝试指定的method,结果中记录允许的method
+func WithMethodProbe(methods ...string) Option {
+	return func(s *Scanner) error {
+		s.opts.ProbeMethods = methods
+		return nil
+	}
+}
+
+// WithHeader 添加一个请求头,可以多次使用
+func WithHeader(name, value string) Option {
+	return func(s *Scanner) error {
+		if name == "" {
+			return fmt.Errorf("invalid header - name is empty")
+		}
+		s.opts.Headers = append(s.opts.Headers, lib.HTTPHeader{Name: name, Value: value})
+		return nil
+	}
+}
+
+// WithCookies 设置请求使用的cookie
+func WithCookies(cookies string) Option {
+	return func(s *Scanner) error {
+		s.opts.Cookies = cookies
+		return nil
+	}
+}
+
+// WithBasicAuth 设置Basic认证
+func WithBasicAuth(username, password string) Option {
+	return func(s *Scanner) error {
+		s.opts.Username = username
+		s.opts.Password = password
+		return nil
+	}
+}
+
+// WithUserAgent 设置User-Agent
+func WithUserAgent(userAgent string) Option {
+	return func(s *Scanner) error {
+		s.opts.UserAgent = userAgent
+		return nil
+	}
+}
+
+// WithFollowRedirect 跟随重定向
+func WithFollowRedirect() Option {
+	return func(s *Scanner) error {
+		s.opts.FollowRedirect = true
+		return nil
+	}
+}
+
+// WithTimeout 设置每个请求的超时时间(默认10s)
+func WithTimeout(timeout time.Duration) Option {
+	return func(s *Scanner) error {
+		if timeout <= 0 {
+			return fmt.Errorf("timeout must be bigger than 0")
+		}
+		s.opts.Timeout = timeout
+		return nil
+	}
+}
+
+// WithProxy 设置代理,多个代理时轮流使用(支持http/https/socks5/socks5h)
+func WithProxy(proxies ...string) Option {
+	return func(s *Scanner) error {
+		s.opts.Proxy = ""
+		s.opts.Proxies = append([]string{}, proxies...)
+		return nil
+	}
+}
+
+// WithInsecureTLS 不校验服务端证书
+func WithInsecureTLS() Option {
+	return func(s *Scanner) error {
+		s.opts.NoTLSValidation = true
+		return nil
+	}
+}
+
+// WithClientCert 使用客户端证书,PEM格式时keyFile为私钥,PKCS#12格式时keyFile为空,password为证书的密码
+func WithClientCert(certFile, keyFile, password string) Option {
+	return func(s *Scanner) error {
+		if certFile == "" {
+			return fmt.Errorf("please provide a client certificate")
+		}
+		s.opts.ClientCertFile = certFile
+		s.opts.ClientKeyFile = keyFile
+		s.opts.ClientCertPass = password
+		return nil
+	}
+}
+
+// WithCACert 使用自定义的CA证书(PEM)校验服务端证书
+func WithCACert(filename string) Option {
+	return func(s *Scanner) error {
+		s.opts.CACertFile = filename
+		return nil
+	}
+}
+
+// WithTLSMinVersion 设置最低的TLS版本(如1.2)
+func WithTLSMinVersion(version string) Option {
+	return func(s *Scanner) error {
+		s.opts.TLSMinVersion = version
+		return nil
+	}
+}
+
+// WithSNI 设置TLS握手时使用的ServerName
+func WithSNI(serverName string) Option {
+	return func(s *Scanner) error {
+		s.opts.SNI = serverName
+		return nil
+	}
+}
+
+// WithHTTP1 只使用HTTP/1.1
+func WithHTTP1() Option {
+	return func(s *Scanner) error {
+		s.opts.ForceHTTP1 = true
+		s.opts.HTTP2 = false
+		return nil
+	}
+}
+
+// WithHTTP2 使用HTTP/2,明文请求使用h2c(经过http代理时明文请求返回错误)
+func WithHTTP2() Option {
+	return func(s *Scanner) error {
+		s.opts.HTTP2 = true
+		s.opts.ForceHTTP1 = false
+		return nil
+	}
+}
+
+// WithConnectionPool 设置每个host的最大连接数(0表示不限制)以及空闲连接的超时时间
+func WithConnectionPool(maxConnsPerHost int, idleTimeout time.Duration) Option {
+	return func(s *Scanner) error {
+		if maxConnsPerHost < 0 || idleTimeout < 0 {
+			return fmt.Errorf("invalid connection pool settings")
+		}
+		s.opts.MaxConnsPerHost = maxConnsPerHost
+		s.opts.IdleConnTimeout = idleTimeout
+		return nil
+	}
+}
+
+// WithNoKeepAlive 每个请求使用新的连接
+func WithNoKeepAlive() Option {
+	return func(s *Scanner) error {
+		s.opts.NoKeepAlive = true
+		return nil
+	}
+}
+
+// WithRateLimit 限制每秒的请求数
+func WithRateLimit(perSecond, burst int) Option {
+	return func(s *Scanner) error {
+		if perSecond < 0 || burst < 1 {
+			return fmt.Errorf("invalid rate limit %d (burst %d)", perSecond, burst)
+		}
+		s.opts.RateLimit = perSecond
+		s.opts.RateBurst = burst
+		return nil
+	}
+}
+
+// WithRetry 对临时性的错误进行重试
+func WithRetry(attempts int, backoff time.Duration) Option {
+	return func(s *Scanner) error {
+		if attempts < 0 || backoff < 0 {
+			return fmt.Errorf("invalid retry settings")
+		}
+		s.opts.RetryAttempts = attempts
+		s.opts.RetryBackoff = backoff
+		return nil
+	}
+}
+
+// WithRetryJitter 重试的等待时间加入随机抖动
+func WithRetryJitter() Option {
+	return func(s *Scanner) error {
+		s.opts.RetryJitter = true
+		return nil
+	}
+}
+
+// WithRetryOnStatus 同样对429和503响应进行重试
+func WithRetryOnStatus() Option {
+	return func(s *Scanner) error {
+		s.opts.RetryOnStatus = true
+		return nil
+	}
+}
+
+// WithErrorHandler 设置处理单个请求出错的函数,默认忽略这些错误
+func WithErrorHandler(fn func(error)) Option {
+	return func(s *Scanner) error {
+		s.onError = fn
+		return nil
+	}
+}